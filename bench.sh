@@ -181,7 +181,7 @@ fi
 
 if [ "$HAS_GO" = "1" ]; then
     echo "Building Go version..."
-    go build -gcflags="-B" -ldflags="-s -w" -o wordcount_go wordcount.go 2>/dev/null
+    go build -gcflags="-B" -ldflags="-s -w" -o wordcount_go ./cmd/wordcount 2>/dev/null
     if [ $? -eq 0 ]; then
         echo "✓ Go build successful"
     else
@@ -222,7 +222,8 @@ echo ""
 extract_counts_from_output() {
     local impl=$1
     local test_file=$2
-    local output=$($impl "$test_file" 2>/dev/null | grep -E "Total words:|Unique words:")
+    # The Go version prints its summary on stderr, hence 2>&1.
+    local output=$($impl "$test_file" 2>&1 | grep -E "Total words:|Unique words:")
     local total=$(echo "$output" | grep "Total words:" | sed 's/[^0-9]//g')
     local unique=$(echo "$output" | grep "Unique words:" | sed 's/[^0-9]//g')
     echo "$total $unique"
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// loadAppend prepares an -append run: the counts saved in filename become
+// cfg.prior, and the results are written back to filename, in the format
+// it was found in, with every word so that the next run can append to it
+// again. A file that does not exist yet starts from no counts and is
+// created in -format.
+func loadAppend(cfg *config, filename string) error {
+	cfg.output = filename
+	cfg.fileTop = 0
+	saved, _, err := readResultsFile(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		cfg.prior = &savedResults{counts: map[string]int{}, complete: true, format: cfg.format}
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Counts started in %s", filename))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	cfg.prior = saved
+	cfg.format = saved.format
+	cfg.compact = cfg.compact && saved.format == formatText
+	cfg.notes = append(cfg.notes, fmt.Sprintf("Counts added to the %s words and %s unique words already in %s",
+		formatNumber(saved.totalWords), formatNumber(int64(len(saved.counts))), filename))
+	if !saved.complete {
+		cfg.notes = append(cfg.notes, "The appended results file listed only its top words (see -top), so the words "+
+			"it left out are counted from this input alone")
+	}
+	return nil
+}
+
+// appendFilters returns the options of cfg, comma separated, that leave
+// words out of the counts or merge them together before the results are
+// written, or "" if there are none. Under -append the results rewrite the
+// saved counts, so those words would be lost from the file.
+func appendFilters(cfg config) string {
+	var names []string
+	if cfg.minCount > 1 {
+		names = append(names, "-min-count")
+	}
+	if cfg.prefix != "" {
+		names = append(names, "-prefix")
+	}
+	if cfg.suffix != "" {
+		names = append(names, "-suffix")
+	}
+	if cfg.opts.MaxUnique > 0 {
+		names = append(names, "-max-unique")
+	}
+	if cfg.groupKey != nil {
+		names = append(names, "-phonetic or -stem")
+	}
+	return strings.Join(names, ", ")
+}
+
+// addPrior adds the counts saved in prior to res. Only the word counts and
+// the total carry over: the length distribution and text statistics
+// cover the new input alone.
+func addPrior(res *wordfreq.Result, prior *savedResults) {
+	if res.Counts == nil {
+		res.Counts = make(map[string]int, len(prior.counts))
+	}
+	for word, count := range prior.counts {
+		res.Counts[word] += count
+	}
+	res.TotalWords += prior.totalWords
+}
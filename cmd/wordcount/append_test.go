@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testConfig returns the settings of a run with default flags, printing
+// nothing on the console.
+func testConfig() config {
+	return config{
+		workers:    1,
+		repeat:     1,
+		quiet:      true,
+		display:    displayCounted,
+		order:      sortCount,
+		format:     formatText,
+		consoleTop: defaultConsoleTop,
+		fileTop:    defaultFileTop,
+		precision:  2,
+		log:        newLogger(false, false, true),
+	}
+}
+
+// appendTo runs -append filename on input with cfg.
+func appendTo(t *testing.T, cfg config, filename, input string) {
+	t.Helper()
+	if err := loadAppend(&cfg, filename); err != nil {
+		t.Fatalf("loadAppend: %v", err)
+	}
+	if err := run(context.Background(), cfg, []string{input}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+}
+
+func TestAppendTwice(t *testing.T) {
+	input := writeFile(t, "input.txt", []byte("the cat and the hat\n"))
+	want := map[string]int{"the": 4, "cat": 2, "and": 2, "hat": 2}
+	for _, format := range []string{formatText, formatJSON, formatJSONL, formatCSV, formatTSV} {
+		filename := filepath.Join(t.TempDir(), "saved"+formatExtensions[format])
+		cfg := testConfig()
+		cfg.format = format
+		appendTo(t, cfg, filename, input)
+		appendTo(t, cfg, filename, input)
+		saved, _, err := readResultsFile(filename)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !maps.Equal(saved.counts, want) || saved.totalWords != 10 {
+			t.Errorf("%s: saved %v (%d words), want %v (10 words)", format, saved.counts, saved.totalWords, want)
+		}
+	}
+}
+
+func TestAppendFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(cfg *config)
+		want string
+	}{
+		{"none", func(cfg *config) {}, ""},
+		{"min-count 1", func(cfg *config) { cfg.minCount = 1 }, ""},
+		{"min-count", func(cfg *config) { cfg.minCount = 2 }, "-min-count"},
+		{"prefix and suffix", func(cfg *config) { cfg.prefix, cfg.suffix = "a", "s" }, "-prefix, -suffix"},
+		{"max-unique", func(cfg *config) { cfg.opts.MaxUnique = 100 }, "-max-unique"},
+		{"stem", func(cfg *config) { cfg.groupKey = strings.ToLower }, "-phonetic or -stem"},
+	}
+	for _, tt := range tests {
+		cfg := testConfig()
+		tt.edit(&cfg)
+		if got := appendFilters(cfg); got != tt.want {
+			t.Errorf("%s: appendFilters = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAppendWithWordFilter(t *testing.T) {
+	// Word options filter only the new input; the saved counts stay.
+	input := writeFile(t, "input.txt", []byte("the cat and the hat\n"))
+	filename := filepath.Join(t.TempDir(), "saved.tsv")
+	cfg := testConfig()
+	cfg.format = formatTSV
+	appendTo(t, cfg, filename, input)
+	cfg.opts.MinLength = 4
+	appendTo(t, cfg, filename, writeFile(t, "more.txt", []byte("the cats and hats\n")))
+	saved, _, err := readResultsFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int{"the": 2, "cat": 1, "and": 1, "hat": 1, "cats": 1, "hats": 1}
+	if !maps.Equal(saved.counts, want) {
+		t.Errorf("saved %v, want %v", saved.counts, want)
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// parseBands parses the -bands list of count thresholds, e.g. "100,10",
+// returning them in descending order without duplicates.
+func parseBands(s string) ([]int, error) {
+	var thresholds []int
+	for _, field := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 2 {
+			return nil, fmt.Errorf("invalid count %q (want an integer >= 2)", field)
+		}
+		thresholds = append(thresholds, n)
+	}
+	slices.Sort(thresholds)
+	slices.Reverse(thresholds)
+	return slices.Compact(thresholds), nil
+}
+
+// band is the words whose counts fall in [lo, hi); hi is 0 for the
+// unbounded top band.
+type band struct {
+	lo, hi int
+	words  []wordfreq.WordCount
+}
+
+// label names the band in results file names: ge100, 10to99, lt10.
+func (b band) label() string {
+	switch {
+	case b.hi == 0:
+		return fmt.Sprintf("ge%d", b.lo)
+	case b.lo <= 1:
+		return fmt.Sprintf("lt%d", b.hi)
+	}
+	return fmt.Sprintf("%dto%d", b.lo, b.hi-1)
+}
+
+// describe says which counts the band holds, for the results header.
+func (b band) describe() string {
+	switch {
+	case b.hi == 0:
+		return fmt.Sprintf("count >= %d", b.lo)
+	case b.lo <= 1:
+		return fmt.Sprintf("count < %d", b.hi)
+	}
+	return fmt.Sprintf("count %d-%d", b.lo, b.hi-1)
+}
+
+// splitBands partitions sorted, in any order, into one band above each of
+// the descending thresholds and a final band below the last. Words keep
+// their order within a band.
+func splitBands(sorted []wordfreq.WordCount, thresholds []int) []band {
+	bands := make([]band, 0, len(thresholds)+1)
+	hi := 0
+	for _, t := range thresholds {
+		bands = append(bands, band{lo: t, hi: hi})
+		hi = t
+	}
+	bands = append(bands, band{lo: 1, hi: hi})
+
+	for _, wc := range sorted {
+		i := 0
+		for i < len(thresholds) && wc.Count < thresholds[i] {
+			i++
+		}
+		bands[i].words = append(bands[i].words, wc)
+	}
+	return bands
+}
+
+// bandName inserts the band label before the extension of a results
+// file name: book_go_results.txt becomes book_go_results_ge100.txt, and a
+// trailing .gz stays last.
+func bandName(name string, b band) string {
+	gz := ""
+	if strings.HasSuffix(name, ".gz") {
+		name, gz = strings.TrimSuffix(name, ".gz"), ".gz"
+	}
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "_" + b.label() + ext + gz
+}
+
+// writeBands writes one results file per band, each holding every word
+// of the band. Percentages stay relative to all words counted.
+func writeBands(con io.Writer, cfg config, rep report) error {
+	name := cfg.output
+	if name == "" {
+		name = resultsName(rep.filenames, cfg.format)
+	}
+	for _, b := range splitBands(rep.sorted, cfg.bands) {
+		bandRep := report{
+			filenames:     rep.filenames,
+			sorted:        b.words,
+			order:         rep.order,
+			totalWords:    rep.totalWords,
+			uniqueWords:   rep.uniqueWords,
+			executionTime: rep.executionTime,
+			precision:     rep.precision,
+			stable:        rep.stable,
+			compact:       rep.compact,
+			charset:       rep.charset,
+			notes: append(slices.Clip(rep.notes), fmt.Sprintf("Frequency band: %s (%s unique words)",
+				b.describe(), formatNumber(int64(len(b.words))))),
+		}
+		if err := writeOutputFile(con, cfg.format, bandName(name, b), cfg.compress, bandRep); err != nil {
+			return err
+		}
+	}
+	return nil
+}
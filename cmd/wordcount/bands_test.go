@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+func TestParseBands(t *testing.T) {
+	got, err := parseBands("10, 100,10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{100, 10}; !slices.Equal(got, want) {
+		t.Errorf("parseBands = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"", "1", "10,x"} {
+		if _, err := parseBands(bad); err == nil {
+			t.Errorf("parseBands(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestBandName(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		b    band
+		want string
+	}{
+		{"book_go_results.txt", band{lo: 100}, "book_go_results_ge100.txt"},
+		{"book_go_results.json.gz", band{lo: 10, hi: 100}, "book_go_results_10to99.json.gz"},
+		{"out", band{lo: 1, hi: 10}, "out_lt10"},
+	} {
+		if got := bandName(tt.name, tt.b); got != tt.want {
+			t.Errorf("bandName(%q, %v) = %q, want %q", tt.name, tt.b, got, tt.want)
+		}
+	}
+}
+
+// TestWriteBands checks that every word lands in the file of its band and
+// that the files read back as results files.
+func TestWriteBands(t *testing.T) {
+	counts := map[string]int{"the": 150, "of": 100, "cat": 99, "hat": 10, "sat": 9, "mat": 1}
+	cfg := testConfig()
+	cfg.format, cfg.bands = formatTSV, []int{100, 10}
+	cfg.output = filepath.Join(t.TempDir(), "words.tsv")
+	rep := report{filenames: []string{"a.txt"}, sorted: wordfreq.Sort(counts), order: sortCount,
+		totalWords: 369, uniqueWords: len(counts), precision: 2}
+	if err := writeBands(io.Discard, cfg, rep); err != nil {
+		t.Fatal(err)
+	}
+	for label, want := range map[string]map[string]int{
+		"ge100":  {"the": 150, "of": 100},
+		"10to99": {"cat": 99, "hat": 10},
+		"lt10":   {"sat": 9, "mat": 1},
+	} {
+		saved, _, err := readResultsFile(filepath.Join(filepath.Dir(cfg.output), "words_"+label+".tsv"))
+		if err != nil {
+			t.Fatalf("band %s: %v", label, err)
+		}
+		if !maps.Equal(saved.counts, want) {
+			t.Errorf("band %s = %v, want %v", label, saved.counts, want)
+		}
+	}
+}
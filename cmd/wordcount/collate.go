@@ -0,0 +1,62 @@
+package main
+
+import (
+	"golang.org/x/text/collate"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// wordOrder returns the comparator of the -sort order. Its ties between
+// words are broken by byte value, unless -collate selected a collator.
+func (cfg config) wordOrder() func(a, b wordfreq.WordCount) bool {
+	return cfg.orderOf(cfg.order)
+}
+
+// orderOf returns the comparator of the -sort order named order, with ties
+// broken like those of wordOrder. Lists always ranked by frequency, such as
+// -bottom, use it so that -collate orders their ties too.
+func (cfg config) orderOf(order string) func(a, b wordfreq.WordCount) bool {
+	if cfg.collator == nil {
+		return sortOrders[order]
+	}
+	return collated(order, cfg.collator)
+}
+
+// collated is the -sort order with words compared in the dictionary order
+// of col, as "a" < "B" < "é" < "z" in most languages, where bytes would put
+// "B" first and "é" last. Words col ranks equal are still compared by
+// bytes, so the order stays total. The collator is not safe for concurrent
+// use, and neither is the comparator.
+func collated(order string, col *collate.Collator) func(a, b wordfreq.WordCount) bool {
+	less := func(a, b string) bool {
+		if c := col.CompareString(a, b); c != 0 {
+			return c < 0
+		}
+		return a < b
+	}
+	byCount := func(a, b wordfreq.WordCount) bool {
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return less(a.Word, b.Word)
+	}
+	switch order {
+	case sortCountAsc:
+		return func(a, b wordfreq.WordCount) bool {
+			if a.Count != b.Count {
+				return a.Count < b.Count
+			}
+			return less(a.Word, b.Word)
+		}
+	case sortAlpha:
+		return func(a, b wordfreq.WordCount) bool { return less(a.Word, b.Word) }
+	case sortLength:
+		return func(a, b wordfreq.WordCount) bool {
+			if len(a.Word) != len(b.Word) {
+				return len(a.Word) > len(b.Word)
+			}
+			return byCount(a, b)
+		}
+	}
+	return byCount
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// words returns the words of list, in order.
+func words(list []wordfreq.WordCount) []string {
+	out := make([]string, len(list))
+	for i, wc := range list {
+		out[i] = wc.Word
+	}
+	return out
+}
+
+func TestCollatedBottom(t *testing.T) {
+	counts := map[string]int{"zoo": 1, "éclair": 1, "apple": 1, "big": 5, "cat": 5}
+	for _, order := range []string{sortCount, sortAlpha, sortLength} {
+		cfg := testConfig()
+		cfg.order, cfg.bottom, cfg.display = order, 3, displayLower
+		cfg.collator = collate.New(language.English)
+		_, rarest := sortCounts(cfg, counts, nil)
+		displayWords(cfg.display, nil, rarest, cfg.orderOf(sortCountAsc))
+		if got, want := words(rarest), []string{"apple", "éclair", "zoo"}; !slices.Equal(got, want) {
+			t.Errorf("-sort %s -collate en -bottom 3 = %v, want %v", order, got, want)
+		}
+	}
+}
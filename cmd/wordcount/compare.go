@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// contrast is one word's frequency in two corpora, scored by Dunning's
+// log-likelihood ratio G². A higher score means the difference in
+// relative frequency is less likely to be chance.
+type contrast struct {
+	word  string
+	a, b  int
+	score float64
+}
+
+// logLikelihood returns G² for a word seen a times in totalA words and b
+// times in totalB words.
+func logLikelihood(a, b int, totalA, totalB int64) float64 {
+	ta, tb := float64(totalA), float64(totalB)
+	expectA := ta * float64(a+b) / (ta + tb)
+	expectB := tb * float64(a+b) / (ta + tb)
+	g := 0.0
+	if a > 0 {
+		g += float64(a) * math.Log(float64(a)/expectA)
+	}
+	if b > 0 {
+		g += float64(b) * math.Log(float64(b)/expectB)
+	}
+	return 2 * g
+}
+
+// contrasts scores every word of either corpus and returns them split by
+// direction, each ordered by descending score with ties broken
+// alphabetically. Words equally frequent in both are left out.
+func contrasts(countsA, countsB map[string]int, totalA, totalB int64) (moreA, moreB []contrast) {
+	score := func(word string, a, b int) {
+		c := contrast{word, a, b, logLikelihood(a, b, totalA, totalB)}
+		// Compare a/totalA with b/totalB without dividing.
+		switch diff := float64(a)*float64(totalB) - float64(b)*float64(totalA); {
+		case diff > 0:
+			moreA = append(moreA, c)
+		case diff < 0:
+			moreB = append(moreB, c)
+		}
+	}
+	for word, a := range countsA {
+		score(word, a, countsB[word])
+	}
+	for word, b := range countsB {
+		if _, ok := countsA[word]; !ok {
+			score(word, 0, b)
+		}
+	}
+	for _, list := range [][]contrast{moreA, moreB} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].score != list[j].score {
+				return list[i].score > list[j].score
+			}
+			return list[i].word < list[j].word
+		})
+	}
+	return moreA, moreB
+}
+
+// runCompare counts filenames and other as two separate corpora and
+// reports the words whose relative frequencies differ most, in each
+// direction. The report goes to the console, and also to -o if given.
+func runCompare(ctx context.Context, cfg config, filenames []string, other string) error {
+	con := cfg.console()
+	cfg.keepAll = true
+	a, err := analyze(ctx, cfg, filenames)
+	if err != nil {
+		return err
+	}
+	b, err := analyze(ctx, cfg, []string{other})
+	if err != nil {
+		return err
+	}
+	if a.totalWords == 0 || b.totalWords == 0 {
+		return fmt.Errorf("%w in one of the corpora", errNoWords)
+	}
+
+	nameA := (&report{filenames: filenames}).inputNames()
+	nameB := displayName(other)
+	moreA, moreB := contrasts(countMap(a.sorted), countMap(b.sorted), a.totalWords, b.totalWords)
+	writeComparison(con, cfg.consoleTop, nameA, nameB, moreA, moreB, a.totalWords, b.totalWords)
+
+	if cfg.output == "" {
+		return nil
+	}
+	out := os.Stdout
+	if cfg.output != stdinName {
+		out, err = os.Create(cfg.output)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	w := bufio.NewWriter(out)
+	writeComparison(w, cfg.fileTop, nameA, nameB, moreA, moreB, a.totalWords, b.totalWords)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if cfg.output != stdinName {
+		fmt.Fprintf(con, "\nComparison written to: %s\n", cfg.output)
+	}
+	return nil
+}
+
+// countMap turns a word list back into a map.
+func countMap(words []wordfreq.WordCount) map[string]int {
+	counts := make(map[string]int, len(words))
+	for _, wc := range words {
+		counts[wc.Word] = wc.Count
+	}
+	return counts
+}
+
+// writeComparison prints the top words of each direction with their counts,
+// rates per million words and log-likelihood scores.
+func writeComparison(w io.Writer, top int, nameA, nameB string, moreA, moreB []contrast, totalA, totalB int64) {
+	fmt.Fprintf(w, "Comparing %s (A, %s words) with %s (B, %s words)\n",
+		nameA, formatNumber(totalA), nameB, formatNumber(totalB))
+	for _, side := range []struct {
+		title string
+		list  []contrast
+	}{
+		{"More Frequent in " + nameA, moreA},
+		{"More Frequent in " + nameB, moreB},
+	} {
+		fmt.Fprintf(w, "\n=== %s ===\n", side.title)
+		fmt.Fprintf(w, "Rank  Word            Count A   Count B   Per M A    Per M B    Log-lik.\n")
+		fmt.Fprintf(w, "----  --------------- --------- --------- ---------- ---------- ---------\n")
+		for i, c := range side.list[:topLimit(top, len(side.list))] {
+			fmt.Fprintf(w, "%4d  %-15s %9s %9s %10.1f %10.1f %9.2f\n", i+1, c.word,
+				formatNumber(int64(c.a)), formatNumber(int64(c.b)),
+				float64(c.a)*1e6/float64(totalA), float64(c.b)*1e6/float64(totalB), c.score)
+		}
+	}
+}
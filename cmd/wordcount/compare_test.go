@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogLikelihood(t *testing.T) {
+	if g := logLikelihood(10, 20, 1000, 2000); g > 1e-9 || g < -1e-9 {
+		t.Errorf("G² of equal rates = %v, want 0", g)
+	}
+	if low, high := logLikelihood(12, 8, 1000, 1000), logLikelihood(30, 10, 1000, 1000); low >= high {
+		t.Errorf("G² of 30 vs 10 (%v) is not above that of 12 vs 8 (%v)", high, low)
+	}
+}
+
+func TestContrasts(t *testing.T) {
+	countsA := map[string]int{"the": 10, "cat": 6, "sat": 4}
+	countsB := map[string]int{"the": 10, "dog": 8, "sat": 2}
+	moreA, moreB := contrasts(countsA, countsB, 20, 20)
+	var gotA, gotB []string
+	for _, c := range moreA {
+		gotA = append(gotA, c.word)
+	}
+	for _, c := range moreB {
+		gotB = append(gotB, c.word)
+	}
+	// "the" is as frequent in both, so it is in neither list.
+	if strings.Join(gotA, " ") != "cat sat" || strings.Join(gotB, " ") != "dog" {
+		t.Errorf("contrasts = %v, %v; want [cat sat], [dog]", gotA, gotB)
+	}
+	if moreA[0].a != 6 || moreA[0].b != 0 {
+		t.Errorf("cat counted %d and %d times, want 6 and 0", moreA[0].a, moreA[0].b)
+	}
+}
+
+func TestRunCompare(t *testing.T) {
+	a := writeFile(t, "a.txt", []byte(strings.Repeat("the cat sat ", 50)))
+	b := writeFile(t, "b.txt", []byte(strings.Repeat("the dog sat ", 50)))
+	cfg := testConfig()
+	cfg.output = filepath.Join(t.TempDir(), "comparison.txt")
+	if err := runCompare(context.Background(), cfg, []string{a}, b); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cfg.output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(data)
+	moreA, moreB, ok := strings.Cut(text, "=== More Frequent in "+b+" ===")
+	if !ok {
+		t.Fatalf("comparison lacks the B section:\n%s", text)
+	}
+	if !strings.Contains(moreA, " cat ") || strings.Contains(moreA, " dog ") ||
+		!strings.Contains(moreB, " dog ") || strings.Contains(moreB, " cat ") {
+		t.Errorf("words are in the wrong sections:\n%s", text)
+	}
+
+	empty := writeFile(t, "empty.txt", nil)
+	if err := runCompare(context.Background(), testConfig(), []string{a}, empty); !errors.Is(err, errNoWords) {
+		t.Errorf("comparing with an empty corpus: error %v, want errNoWords", err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// concordance is where one word occurs in one input.
+type concordance struct {
+	name        string
+	lines       []lineMatch
+	occurrences int
+}
+
+// lineMatch is a line the word occurs on, numbered from 1.
+type lineMatch struct {
+	number int
+	text   string
+}
+
+// concordanceKey returns word as the counted key it is matched by, folded
+// and length-checked under opts.
+func concordanceKey(word string, opts wordfreq.Options) (string, error) {
+	data := []byte(word)
+	key, end, ok := wordfreq.ExtractWord(data, 0, nil, opts)
+	if !ok {
+		return "", fmt.Errorf("%q is not a word under the word options given", word)
+	}
+	if _, _, more := wordfreq.ExtractWord(data, end, nil, opts); more {
+		return "", fmt.Errorf("%q is more than one word; give a single word", word)
+	}
+	return string(key), nil
+}
+
+// findLines reads r line by line and records the lines on which key is
+// one of the words, found as ExtractWord finds them. ctx is checked
+// between lines.
+func findLines(ctx context.Context, r io.Reader, key string, opts wordfreq.Options) ([]lineMatch, int, error) {
+	var lines []lineMatch
+	occurrences := 0
+	buf := make([]byte, 0, wordfreq.MaxWordLength)
+	br := bufio.NewReader(r)
+	for number := 1; ; number++ {
+		if err := ctx.Err(); err != nil {
+			return lines, occurrences, err
+		}
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 {
+			line = bytes.TrimRight(line, "\r\n")
+			hits := 0
+			for pos := 0; pos < len(line); {
+				word, end, ok := wordfreq.ExtractWord(line, pos, buf, opts)
+				if ok && string(word) == key {
+					hits++
+				}
+				if end <= pos {
+					break
+				}
+				pos = end
+			}
+			if hits > 0 {
+				lines = append(lines, lineMatch{number: number, text: string(line)})
+				occurrences += hits
+			}
+		}
+		if err == io.EOF {
+			return lines, occurrences, nil
+		}
+		if err != nil {
+			return lines, occurrences, err
+		}
+	}
+}
+
+// concordFile finds key in the lines of filename, decompressed and decoded
+// as it would be counted.
+func concordFile(ctx context.Context, cfg config, filename, key string) (*concordance, error) {
+	var input io.Reader = os.Stdin
+	if filename != stdinName {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		input = file
+	}
+	if isGzip(cfg, filename) {
+		gz, err := gzip.NewReader(input)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", displayName(filename), err)
+		}
+		defer gz.Close()
+		input = gz
+	}
+	text, err := skipBOM(cfg, input)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", displayName(filename), err)
+	}
+	lines, occurrences, err := findLines(ctx, decodeInput(cfg, text), key, cfg.opts)
+	c := &concordance{name: displayName(filename), lines: lines, occurrences: occurrences}
+	if err != nil {
+		return c, fmt.Errorf("%s: %w", displayName(filename), err)
+	}
+	return c, nil
+}
+
+// writeConcordance lists each line of found as "line: text", prefixed
+// with the input name as grep does when there is more than one input.
+func writeConcordance(w io.Writer, found []*concordance) {
+	for _, c := range found {
+		for _, line := range c.lines {
+			if len(found) > 1 {
+				fmt.Fprintf(w, "%s:", c.name)
+			}
+			fmt.Fprintf(w, "%d: %s\n", line.number, line.text)
+		}
+	}
+}
+
+// runConcordance lists the lines of filenames on which word occurs, on
+// stdout or in -o PATH, and sums them up on the console. Inputs are read
+// in full, whatever -limit-bytes says, since lines are numbered from the
+// start.
+func runConcordance(ctx context.Context, cfg config, filenames []string, word string) error {
+	con := cfg.console()
+	key, err := concordanceKey(word, cfg.opts)
+	if err != nil {
+		return err
+	}
+
+	var found []*concordance
+	var stopped error
+	for _, filename := range filenames {
+		c, err := concordFile(ctx, cfg, filename, key)
+		if c == nil {
+			return err
+		}
+		found = append(found, c)
+		if err != nil {
+			stopped = err
+			break
+		}
+	}
+
+	out := os.Stdout
+	if cfg.output != "" && cfg.output != stdinName {
+		out, err = os.Create(cfg.output)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	w := bufio.NewWriter(out)
+	writeConcordance(w, found)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	occurrences, lines := 0, 0
+	for _, c := range found {
+		occurrences += c.occurrences
+		lines += len(c.lines)
+	}
+	fmt.Fprintf(con, "\n%q occurs %s times on %s lines\n", key, formatNumber(int64(occurrences)), formatNumber(int64(lines)))
+	if cfg.output != "" && cfg.output != stdinName {
+		if err := out.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(con, "Concordance written to: %s\n", cfg.output)
+	}
+	return stopped
+}
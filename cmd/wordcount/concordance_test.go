@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+func TestConcordanceKey(t *testing.T) {
+	if key, err := concordanceKey("Cat", wordfreq.Options{}); err != nil || key != "cat" {
+		t.Errorf(`concordanceKey("Cat") = %q, %v; want "cat"`, key, err)
+	}
+	for _, bad := range []string{"", "42", "two words"} {
+		if _, err := concordanceKey(bad, wordfreq.Options{}); err == nil {
+			t.Errorf("concordanceKey(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestFindLines(t *testing.T) {
+	text := "The cat sat.\r\nno match: cats, concat\nCAT and cat\n\ncat"
+	lines, occurrences, err := findLines(context.Background(), strings.NewReader(text), "cat", wordfreq.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []lineMatch{{1, "The cat sat."}, {3, "CAT and cat"}, {5, "cat"}}
+	if len(lines) != len(want) || occurrences != 4 {
+		t.Fatalf("findLines = %v, %d occurrences; want %v, 4", lines, occurrences, want)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %v, want %v", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestRunConcordance(t *testing.T) {
+	a := writeFile(t, "a.txt", []byte("one cat\ntwo dogs\n"))
+	b := writeFile(t, "b.txt", []byte("no\nCat again\n"))
+	cfg := testConfig()
+	cfg.output = filepath.Join(t.TempDir(), "concordance.txt")
+	if err := runConcordance(context.Background(), cfg, []string{a, b}, "CAT"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cfg.output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// With more than one input, each line is prefixed with its input's name.
+	if want := a + ":1: one cat\n" + b + ":2: Cat again\n"; string(data) != want {
+		t.Errorf("concordance = %q, want %q", data, want)
+	}
+}
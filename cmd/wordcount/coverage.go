@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// coveragePoint is the number of most frequent unique words that together
+// account for at least percent of all word occurrences.
+type coveragePoint struct {
+	Percent float64 `json:"percent"`
+	Words   int     `json:"unique_words"`
+}
+
+// parseCoverage parses the -coverage list of percentages, e.g. "50,80,90",
+// returning them in ascending order.
+func parseCoverage(s string) ([]float64, error) {
+	var thresholds []float64
+	for _, field := range strings.Split(s, ",") {
+		p, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentage %q (want a number in (0, 100])", field)
+		}
+		thresholds = append(thresholds, p)
+	}
+	sort.Float64s(thresholds)
+	return thresholds, nil
+}
+
+// coverage walks words, which must be ordered by descending count, and
+// returns the rank at which the cumulative count first reaches each of the
+// ascending thresholds.
+func coverage(words []wordfreq.WordCount, totalWords int64, thresholds []float64) []coveragePoint {
+	points := make([]coveragePoint, 0, len(thresholds))
+	var cumulative int64
+	rank := 0
+	for _, p := range thresholds {
+		need := p / 100 * float64(totalWords)
+		for rank < len(words) && float64(cumulative) < need {
+			cumulative += int64(words[rank].Count)
+			rank++
+		}
+		points = append(points, coveragePoint{Percent: p, Words: rank})
+	}
+	return points
+}
+
+// writeCoverage prints one line per coverage point.
+func writeCoverage(w io.Writer, points []coveragePoint, uniqueWords int) {
+	for _, pt := range points {
+		share := 0.0
+		if uniqueWords > 0 {
+			share = float64(pt.Words) * 100 / float64(uniqueWords)
+		}
+		fmt.Fprintf(w, "%6.2f%% of words: %9s most frequent unique words (%.2f%% of vocabulary)\n",
+			pt.Percent, formatNumber(int64(pt.Words)), share)
+	}
+}
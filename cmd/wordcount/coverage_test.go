@@ -0,0 +1,39 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+func TestParseCoverage(t *testing.T) {
+	got, err := parseCoverage("90, 50,100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []float64{50, 90, 100}; !slices.Equal(got, want) {
+		t.Errorf("parseCoverage = %v, want %v", got, want)
+	}
+	for _, bad := range []string{"", "0", "101", "50,x", "-5"} {
+		if _, err := parseCoverage(bad); err == nil {
+			t.Errorf("parseCoverage(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	words := wordfreq.Sort(map[string]int{"the": 50, "of": 30, "cat": 10, "hat": 5, "sat": 5})
+	got := coverage(words, 100, []float64{10, 50, 80, 81, 100})
+	want := []coveragePoint{{10, 1}, {50, 1}, {80, 2}, {81, 3}, {100, 5}}
+	if !slices.Equal(got, want) {
+		t.Errorf("coverage = %v, want %v", got, want)
+	}
+
+	// Words left out of the list still count towards the total, so a
+	// threshold may never be reached; it then takes every listed word.
+	got = coverage(words, 200, []float64{25, 75})
+	if want := []coveragePoint{{25, 1}, {75, 5}}; !slices.Equal(got, want) {
+		t.Errorf("coverage of a partial list = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// loadDictionary builds cfg.dict from the -dict file, normalizing its words
+// like loadStopWords does so they match the counted keys.
+func loadDictionary(cfg *config, filename string) error {
+	if filename == "" {
+		return nil
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	words, err := wordfreq.ReadWordSet(normalizeText(*cfg, file), cfg.opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	cfg.dict = words
+	cfg.notes = append(cfg.notes, fmt.Sprintf("Words checked against the dictionary %s (%d words)", filename, len(words)))
+	return nil
+}
+
+// notInDictionary returns the words, in the order given, that dict does
+// not list: candidate misspellings, OCR errors or domain terms. Words are
+// folded under opts first, since -display may have restored their case.
+func notInDictionary(words []wordfreq.WordCount, dict map[string]struct{}, opts wordfreq.Options) []wordfreq.WordCount {
+	var unknown []wordfreq.WordCount
+	for _, wc := range words {
+		if _, ok := dict[foldCase(opts, wc.Word)]; !ok {
+			unknown = append(unknown, wc)
+		}
+	}
+	return unknown
+}
+
+// unknownSummary describes how much of the text the dictionary missed.
+func unknownSummary(unknown []wordfreq.WordCount, uniqueWords int, totalWords int64) string {
+	var occurrences int64
+	for _, wc := range unknown {
+		occurrences += int64(wc.Count)
+	}
+	return fmt.Sprintf("%s of %s unique words are not in the dictionary (%s of %s occurrences)",
+		formatNumber(int64(len(unknown))), formatNumber(int64(uniqueWords)),
+		formatNumber(occurrences), formatNumber(totalWords))
+}
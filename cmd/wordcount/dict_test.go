@@ -0,0 +1,31 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+func TestNotInDictionary(t *testing.T) {
+	cfg := testConfig()
+	dictFile := writeFile(t, "dict.txt", []byte("The\ncat\nSAT\n"))
+	if err := loadDictionary(&cfg, dictFile); err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.dict) != 3 || !strings.Contains(cfg.notes[len(cfg.notes)-1], "(3 words)") {
+		t.Fatalf("dictionary %v with notes %q, want 3 words", cfg.dict, cfg.notes)
+	}
+
+	// -display may have restored the case of "Cat", which still matches.
+	counted := wordfreq.Sort(map[string]int{"the": 6, "Cat": 3, "teh": 2, "sat": 1, "mat": 1})
+	unknown := notInDictionary(counted, cfg.dict, cfg.opts)
+	if got, want := words(unknown), []string{"teh", "mat"}; !slices.Equal(got, want) {
+		t.Errorf("notInDictionary = %v, want %v", got, want)
+	}
+	want := "2 of 5 unique words are not in the dictionary (3 of 13 occurrences)"
+	if got := unknownSummary(unknown, len(counted), 13); got != want {
+		t.Errorf("unknownSummary = %q, want %q", got, want)
+	}
+}
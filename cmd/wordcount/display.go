@@ -0,0 +1,50 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// -display modes: how the listed words are written, independently of how
+// letter case was folded while counting.
+const (
+	displayCounted = "counted" // exactly as counted
+	displayLower   = "lower"   // lowercased, even under -case-sensitive
+	displayFirst   = "first"   // as first written in the input
+	displayCommon  = "common"  // as most often written in the input
+)
+
+// displayModes maps each -display mode to the spellings the library has
+// to keep for it.
+var displayModes = map[string]wordfreq.Spelling{
+	displayCounted: wordfreq.SpellingNone,
+	displayLower:   wordfreq.SpellingNone,
+	displayFirst:   wordfreq.SpellingFirst,
+	displayCommon:  wordfreq.SpellingCommon,
+}
+
+// displayWords rewrites the words of list in place for -display mode,
+// using the spellings counted alongside them, and restores the list's
+// order, less, as the new text can change how ties fall; a nil less keeps
+// the order as it is. Words written alike stay separate entries.
+func displayWords(mode string, spellings map[string]*wordfreq.Spellings, list []wordfreq.WordCount, less func(a, b wordfreq.WordCount) bool) {
+	if mode == displayCounted {
+		return
+	}
+	for i := range list {
+		s, ok := spellings[list[i].Word]
+		switch {
+		case mode == displayLower:
+			list[i].Word = strings.ToLower(list[i].Word)
+		case ok && mode == displayFirst:
+			list[i].Word = s.First
+		case ok:
+			list[i].Word = s.Common()
+		}
+	}
+	if less != nil {
+		sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/unicode/norm"
+)
+
+// encodingUTF8 is the default -encoding: input bytes are counted as they
+// are, as UTF-8 with -unicode and as ASCII otherwise.
+const encodingUTF8 = "utf8"
+
+// encodings maps each -encoding name to the single-byte character set it
+// decodes from. UTF-8 input needs no decoding.
+var encodings = map[string]*charmap.Charmap{
+	encodingUTF8:   nil,
+	"latin1":       charmap.ISO8859_1,
+	"windows-1252": charmap.Windows1252,
+}
+
+// outputEncodings maps each -output-encoding name to the character set
+// results files are written in. Characters a set lacks are replaced by its
+// substitute byte rather than failing the write.
+var outputEncodings = map[string]encoding.Encoding{
+	encodingUTF8:   nil,
+	"latin1":       charmap.ISO8859_1,
+	"windows-1252": charmap.Windows1252,
+	"shift-jis":    japanese.ShiftJIS,
+	"euc-jp":       japanese.EUCJP,
+	"euc-kr":       korean.EUCKR,
+	"gbk":          simplifiedchinese.GBK,
+	"big5":         traditionalchinese.Big5,
+}
+
+// normForms maps each -normalize name to its Unicode normalization form.
+// Only the composed forms are offered: the tokenizer ends a word at a
+// combining mark, so decomposing "é" into "e" and U+0301 would cut it off.
+var normForms = map[string]norm.Form{
+	"nfc":  norm.NFC,
+	"nfkc": norm.NFKC,
+}
+
+// sortedKeys returns the keys of an option table, sorted and comma
+// separated, for error messages.
+func sortedKeys[V any](m map[string]V) string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// utf8BOM is the byte-order mark some Windows tools write at the start of
+// UTF-8 files.
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// errUTF16 reports input that starts with a UTF-16 byte-order mark.
+var errUTF16 = errors.New("input is UTF-16 (it starts with a UTF-16 byte-order mark); convert it to UTF-8 first, e.g. with iconv -f UTF-16 -t UTF-8")
+
+// bomLength returns the length of the UTF-8 byte-order mark that prefix,
+// the first bytes of an input, starts with, or 0 if it has none. A UTF-16
+// mark is an error unless a legacy -encoding says the bytes mean
+// something else.
+func bomLength(cfg config, prefix []byte) (int, error) {
+	if bytes.HasPrefix(prefix, utf8BOM) {
+		return len(utf8BOM), nil
+	}
+	if cfg.charset == nil && (bytes.HasPrefix(prefix, []byte{0xFF, 0xFE}) || bytes.HasPrefix(prefix, []byte{0xFE, 0xFF})) {
+		return 0, errUTF16
+	}
+	return 0, nil
+}
+
+// sniffSize is how much of the start of an input checkText looks at.
+const sniffSize = 4096
+
+// errBinary reports input that does not look like text.
+var errBinary = errors.New("input looks like binary data, not text; use -force to count it anyway")
+
+// checkText returns errBinary if more than a tenth of sample, the first
+// bytes of an input, are control characters other than whitespace or, for
+// UTF-8 input, bytes that are not valid UTF-8. Text has next to none of
+// either, while images, archives and executables are full of both. -force
+// skips the check.
+func checkText(cfg config, sample []byte) error {
+	if cfg.force {
+		return nil
+	}
+	suspect := 0
+	for i := 0; i < len(sample); {
+		b := sample[i]
+		switch {
+		case b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' && b != '\v', b == 0x7F:
+			suspect++
+		case b >= utf8.RuneSelf && cfg.charset == nil:
+			r, size := utf8.DecodeRune(sample[i:])
+			if r == utf8.RuneError && size == 1 && utf8.FullRune(sample[i:]) {
+				suspect++
+			}
+			i += size
+			continue
+		}
+		i++
+	}
+	if suspect*10 > len(sample) {
+		return errBinary
+	}
+	return nil
+}
+
+// skipBOM returns r without the byte-order mark it may start with; see
+// bomLength. It also refuses input that checkText takes for binary.
+func skipBOM(cfg config, r io.Reader) (io.Reader, error) {
+	br := bufio.NewReaderSize(r, sniffSize)
+	prefix, err := br.Peek(sniffSize)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	n, err := bomLength(cfg, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkText(cfg, prefix[n:]); err != nil {
+		return nil, err
+	}
+	br.Discard(n)
+	return br, nil
+}
+
+// decodeInput wraps r so that it yields the UTF-8 text that is counted:
+// decoded from a legacy -encoding, then normalized with -normalize.
+func decodeInput(cfg config, r io.Reader) io.Reader {
+	if cfg.charset != nil {
+		r = cfg.charset.NewDecoder().Reader(r)
+	}
+	return normalizeText(cfg, r)
+}
+
+// normalizeText applies -normalize to r, which must already be UTF-8.
+func normalizeText(cfg config, r io.Reader) io.Reader {
+	if cfg.normalize == "" {
+		return r
+	}
+	return normForms[cfg.normalize].Reader(r)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// writeFile writes data to name in a test's temporary directory and
+// returns its path.
+func writeFile(tb testing.TB, name string, data []byte) string {
+	tb.Helper()
+	path := filepath.Join(tb.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		tb.Fatal(err)
+	}
+	return path
+}
+
+func TestCountFileBOM(t *testing.T) {
+	text := append(append([]byte(nil), utf8BOM...), "hello world hello\n"...)
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(text)
+	zw.Close()
+	plain := writeFile(t, "bom.txt", text)
+	compressed := writeFile(t, "bom.txt.gz", gz.Bytes())
+
+	want := map[string]int{"hello": 2, "world": 1}
+	for _, unicode := range []bool{false, true} {
+		opts := wordfreq.Options{Unicode: unicode}
+		tests := []struct {
+			name     string
+			filename string
+			cfg      config
+		}{
+			{"stream", plain, config{opts: opts, workers: 1}},
+			{"mmap", plain, config{opts: opts, workers: 1, mmap: true}},
+			{"gzip", compressed, config{opts: opts, workers: 1}},
+			{"parallel", plain, config{opts: opts, workers: 4}},
+			{"parallel mmap", plain, config{opts: opts, workers: 4, mmap: true}},
+		}
+		for _, tt := range tests {
+			res, _, err := countFile(context.Background(), tt.cfg, tt.filename)
+			if err != nil {
+				t.Fatalf("%s, unicode %v: %v", tt.name, unicode, err)
+			}
+			if !maps.Equal(res.Counts, want) {
+				t.Errorf("%s, unicode %v: got %q, want %q", tt.name, unicode, res.Counts, want)
+			}
+		}
+	}
+}
+
+func TestCountFileUTF16(t *testing.T) {
+	for _, bom := range [][]byte{{0xFF, 0xFE}, {0xFE, 0xFF}} {
+		filename := writeFile(t, "utf16.txt", append(bom, "h\x00i\x00"...))
+		for _, mmap := range []bool{false, true} {
+			cfg := config{workers: 1, mmap: mmap}
+			if _, _, err := countFile(context.Background(), cfg, filename); !errors.Is(err, errUTF16) {
+				t.Errorf("BOM % X, mmap %v: error %v, want %v", bom, mmap, err, errUTF16)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// freqBucket is the number of unique words whose counts lie in [lo, hi].
+type freqBucket struct {
+	lo, hi int
+	words  int
+}
+
+// freqHistogram buckets words by how often each occurs: exactly once,
+// twice, then 3-10, 11-100 and so on by powers of ten. Empty buckets are
+// left out.
+func freqHistogram(words []wordfreq.WordCount) []freqBucket {
+	var buckets []freqBucket
+	index := make(map[int]int) // bucket lo -> index in buckets
+	for _, wc := range words {
+		lo, hi := wc.Count, wc.Count
+		if wc.Count > 2 {
+			lo, hi = 3, 10
+			for wc.Count > hi {
+				lo, hi = hi+1, hi*10
+			}
+		}
+		i, ok := index[lo]
+		if !ok {
+			i = len(buckets)
+			index[lo] = i
+			buckets = append(buckets, freqBucket{lo: lo, hi: hi})
+		}
+		buckets[i].words++
+	}
+	slices.SortFunc(buckets, func(a, b freqBucket) int { return a.lo - b.lo })
+	return buckets
+}
+
+// writeFreqHistogram prints one line per bucket with its share of the
+// vocabulary.
+func writeFreqHistogram(w io.Writer, buckets []freqBucket, uniqueWords int) {
+	fmt.Fprintf(w, "Occurrences  Unique words  Share\n")
+	fmt.Fprintf(w, "-----------  ------------  -------\n")
+	for _, b := range buckets {
+		label := fmt.Sprintf("%d-%d", b.lo, b.hi)
+		if b.lo == b.hi {
+			label = fmt.Sprint(b.lo)
+		}
+		fmt.Fprintf(w, "%11s  %12s  %6.2f%%\n", label, formatNumber(int64(b.words)),
+			share(int64(b.words), int64(uniqueWords)))
+	}
+}
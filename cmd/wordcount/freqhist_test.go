@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+func TestFreqHistogram(t *testing.T) {
+	words := wordfreq.Sort(map[string]int{"the": 150, "of": 100, "cat": 11, "and": 10, "hat": 3, "sat": 2, "mat": 1, "bat": 1})
+	got := freqHistogram(words)
+	want := []freqBucket{{1, 1, 2}, {2, 2, 1}, {3, 10, 2}, {11, 100, 2}, {101, 1000, 1}}
+	if !slices.Equal(got, want) {
+		t.Errorf("freqHistogram = %v, want %v", got, want)
+	}
+
+	var doc bytes.Buffer
+	rep := &report{filenames: []string{"a.txt"}, uniqueWords: len(words), freqHist: got}
+	if err := writeJSON(&doc, rep); err != nil {
+		t.Fatal(err)
+	}
+	var parsed jsonReport
+	if err := json.Unmarshal(doc.Bytes(), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	wantJSON := []jsonFreqBin{{1, 1, 2, 25}, {2, 2, 1, 12.5}, {3, 10, 2, 25}, {11, 100, 2, 25}, {101, 1000, 1, 12.5}}
+	if !slices.Equal(parsed.FreqHist, wantJSON) {
+		t.Errorf("JSON freq_hist = %v, want %v", parsed.FreqHist, wantJSON)
+	}
+}
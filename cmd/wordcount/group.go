@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// groupWords merges the counts of words that share a key, such as a
+// Soundex code or a stem. Each group is shown as its most frequent word
+// (alphabetically first on a tie); variants lists the words of every group
+// that has more than one, most frequent first.
+func groupWords(counts map[string]int, key func(string) string) (grouped map[string]int, variants map[string][]wordfreq.WordCount) {
+	byKey := make(map[string][]wordfreq.WordCount)
+	for word, n := range counts {
+		k := key(word)
+		byKey[k] = append(byKey[k], wordfreq.WordCount{Word: word, Count: n})
+	}
+
+	grouped = make(map[string]int, len(byKey))
+	variants = make(map[string][]wordfreq.WordCount)
+	for _, spellings := range byKey {
+		sort.Slice(spellings, func(i, j int) bool { return wordfreq.ByCount(spellings[i], spellings[j]) })
+		total := 0
+		for _, wc := range spellings {
+			total += wc.Count
+		}
+		rep := spellings[0].Word
+		grouped[rep] = total
+		if len(spellings) > 1 {
+			variants[rep] = spellings
+		}
+	}
+	return grouped, variants
+}
+
+// writeVariants prints the words grouped under each of words that has more
+// than one.
+func writeVariants(w io.Writer, words []wordfreq.WordCount, variants map[string][]wordfreq.WordCount) {
+	for _, wc := range words {
+		spellings, ok := variants[wc.Word]
+		if !ok {
+			continue
+		}
+		parts := make([]string, len(spellings))
+		for i, s := range spellings {
+			parts[i] = fmt.Sprintf("%s (%s)", s.Word, formatNumber(int64(s.Count)))
+		}
+		fmt.Fprintf(w, "%-15s %s\n", wc.Word, strings.Join(parts, ", "))
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+func TestGroupWords(t *testing.T) {
+	counts := map[string]int{"run": 3, "running": 5, "runs": 1, "cat": 2}
+	grouped, variants := groupWords(counts, wordfreq.Stem)
+	if want := map[string]int{"running": 9, "cat": 2}; !maps.Equal(grouped, want) {
+		t.Errorf("grouped = %v, want %v", grouped, want)
+	}
+	if got, want := variants["running"], wordfreq.Sort(map[string]int{"running": 5, "run": 3, "runs": 1}); !slices.Equal(got, want) {
+		t.Errorf("variants[running] = %v, want %v", got, want)
+	}
+	if _, ok := variants["cat"]; ok {
+		t.Error("a group of one word has variants")
+	}
+
+	sorted := wordfreq.Sort(grouped)
+	rep := &report{filenames: []string{"a.txt"}, sorted: sorted, uniqueWords: len(sorted), top: 10, variants: variants}
+
+	var text bytes.Buffer
+	writeText(&text, rep)
+	if want := "running (5), run (3), runs (1)"; !strings.Contains(text.String(), want) {
+		t.Errorf("text output lacks %q:\n%s", want, text.String())
+	}
+
+	var doc bytes.Buffer
+	if err := writeJSON(&doc, rep); err != nil {
+		t.Fatal(err)
+	}
+	var parsed jsonReport
+	if err := json.Unmarshal(doc.Bytes(), &parsed); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]jsonlEntry{"running": {{"running", 5}, {"run", 3}, {"runs", 1}}}
+	if !maps.EqualFunc(parsed.Variants, want, slices.Equal) {
+		t.Errorf("JSON variants = %v, want %v", parsed.Variants, want)
+	}
+}
@@ -0,0 +1,1699 @@
+// wordcount - Word frequency counter (Go implementation)
+// Build: go build -ldflags="-s -w" -o wordcount_go ./cmd/wordcount
+// Usage: ./wordcount_go [flags] [filename|- ...]
+
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	_ "embed"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"os"
+	"os/signal"
+	"regexp"
+	"runtime"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+	"golang.org/x/text/collate"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/language"
+)
+
+const (
+	defaultConsoleTop = 10
+	defaultFileTop    = 100
+
+	// stdinName is the filename argument that selects standard input.
+	stdinName = "-"
+
+	// exitNoWords is the exit status for input without words under
+	// -fail-empty. Other failures exit with 1.
+	exitNoWords = 2
+)
+
+// errNoWords is returned by present under -fail-empty when nothing was
+// counted. The results are still written first.
+var errNoWords = errors.New("no words were counted")
+
+// defaultStopWords is the built-in English list selected by
+// -stopwords-default.
+//
+//go:embed stopwords_en.txt
+var defaultStopWords string
+
+// config holds the settings parsed from the command line.
+type config struct {
+	opts       wordfreq.Options
+	workers    int
+	consoleTop int
+	bottom     int // least frequent words to show (0 = none)
+	minCount   int // drop words counted fewer times than this
+	fileTop    int
+	format     string
+	order      string              // -sort order of the word lists
+	collator   *collate.Collator   // -collate order of tied words; nil for bytes
+	precision  int                 // decimal places of percentages
+	stable     bool                // keep results files identical across runs
+	output     string              // results file path; derived from the input when empty
+	gzip       bool                // decompress every input, not only *.gz files
+	charset    *charmap.Charmap    // -encoding of the input; nil for UTF-8
+	normalize  string              // -normalize form applied to the text, if any
+	progress   bool                // report progress on stderr while counting
+	mmap       bool                // count files through a memory mapping
+	failEmpty  bool                // exit with exitNoWords when nothing is counted
+	coverage   []float64           // -coverage percentages, ascending
+	perLength  int                 // most frequent words to show for each length
+	freqHist   bool                // report how many words occur how often
+	bands      []int               // -bands count thresholds, descending
+	groupKey   func(string) string // -phonetic or -stem key words are grouped by
+	display    string              // -display mode of the listed words
+	quiet      bool                // print nothing but errors; only write the results
+	noFile     bool                // print the summary but write no results file
+	repeat     int                 // times to count the input; the last run is reported
+	metrics    string              // -metrics JSON file, if any
+	baseline   *jsonMetrics        // -baseline metrics the run is compared with
+	baseName   string              // the -baseline file name
+	maxSlow    float64             // -max-slowdown percent past which the -baseline comparison fails; 0 for none
+	compress   bool                // gzip the results files
+	outCharset encoding.Encoding   // -output-encoding of the results files; nil for UTF-8
+	repl       bool                // answer queries on stdin after counting
+	countOnly  bool                // report only the total, keeping no counts
+	compact    bool                // -format text word lists without padding
+	force      bool                // count input that looks like binary data
+	prior      *savedResults       // -append counts the new input is added to
+	log        *slog.Logger        // -v and -vv diagnostics on stderr
+	dict       map[string]struct{} // -dict words, normalized; nil without it
+	prefix     string              // list only words starting with this, case-folded
+	suffix     string              // list only words ending with this, case-folded
+	notes      []string            // settings worth recording in the results header
+	keepAll    bool                // sort every word, even when only the top ones are shown
+}
+
+// console returns where the human-readable summary is printed: stderr, so
+// that stdout only ever carries results (with -o -), or nowhere with
+// -quiet.
+func (cfg config) console() io.Writer {
+	if cfg.quiet {
+		return io.Discard
+	}
+	return os.Stderr
+}
+
+// newLogger returns the logger for diagnostics, which go to stderr apart
+// from the summary: warnings by default, information about each input
+// with -v, and the reading loops' debug records with -vv. -quiet leaves
+// only errors.
+func newLogger(verbose, debug, quiet bool) *slog.Logger {
+	level := slog.LevelWarn
+	switch {
+	case quiet:
+		level = slog.LevelError
+	case debug:
+		level = slog.LevelDebug
+	case verbose:
+		level = slog.LevelInfo
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
+
+// hasAffixes reports whether word is listed under -prefix and -suffix.
+func (cfg config) hasAffixes(word string) bool {
+	return strings.HasPrefix(word, cfg.prefix) && strings.HasSuffix(word, cfg.suffix)
+}
+
+// foldCase lowercases s the way words are folded under opts: not at all
+// when case-sensitive, and only ASCII letters unless Unicode is set.
+func foldCase(opts wordfreq.Options, s string) string {
+	switch {
+	case opts.CaseSensitive:
+		return s
+	case opts.Unicode:
+		return strings.ToLower(s)
+	}
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, s)
+}
+
+// Heaps' law estimates the vocabulary of a text of n words as k*n^0.5 for
+// natural language; k is typically 10-100. Words average about 6 bytes
+// with their separators, and gzip shrinks text about 3 times.
+const (
+	heapsK       = 40
+	bytesPerWord = 6
+	gzipRatio    = 3
+	minStoreSize = 16384 // the library default
+	maxStoreSize = 1 << 22
+)
+
+// storeSize picks the initial count store capacity for a file of the
+// given size from Heaps' law, so big inputs rehash less. It is never
+// below the library default, and each parallel worker, which sees only
+// its share of the text, gets a store sized for that share.
+func storeSize(cfg config, fileBytes int64, compressed bool) int {
+	if compressed {
+		fileBytes *= gzipRatio
+	}
+	words := float64(fileBytes) / bytesPerWord
+	if cfg.workers > 1 && cfg.opts.Hash != wordfreq.HashSharded {
+		words /= float64(cfg.workers)
+	}
+	estimate := heapsK * math.Sqrt(words)
+	return int(min(max(estimate, minStoreSize), maxStoreSize))
+}
+
+// countingReader tracks how many bytes have been read from r, so input
+// size can be reported for streams that cannot be stat'ed (stdin). The
+// count is atomic so -progress can sample it while counting runs.
+type countingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n.Add(int64(n))
+	return n, err
+}
+
+func (c *countingReader) bytesRead() int64 {
+	return c.n.Load()
+}
+
+// parseByteSize parses a byte count such as "65536", "256K" or "1M". The
+// K, M and G suffixes are powers of 1024 and may be followed by "B".
+func parseByteSize(s string) (int, error) {
+	num := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(s)), "B")
+	shift := 0
+	switch {
+	case strings.HasSuffix(num, "K"):
+		shift = 10
+	case strings.HasSuffix(num, "M"):
+		shift = 20
+	case strings.HasSuffix(num, "G"):
+		shift = 30
+	}
+	if shift > 0 {
+		num = num[:len(num)-1]
+	}
+	n, err := strconv.Atoi(num)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	if n > math.MaxInt>>shift {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+	return n << shift, nil
+}
+
+// parseDelimiter parses the -delimiter character: a single ASCII
+// character, or a Go escape such as \t or \n.
+func parseDelimiter(s string) (byte, error) {
+	if len(s) != 1 {
+		unquoted, err := strconv.Unquote(`"` + s + `"`)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a single character", s)
+		}
+		s = unquoted
+	}
+	if len(s) != 1 || s[0] == 0 || s[0] >= utf8.RuneSelf {
+		return 0, fmt.Errorf("%q is not a single ASCII character", s)
+	}
+	return s[0], nil
+}
+
+func formatNumber(n int64) string {
+	str := strconv.FormatInt(n, 10)
+	// The sign is set aside so that no comma follows it.
+	sign := ""
+	if n < 0 {
+		sign, str = "-", str[1:]
+	}
+	if len(str) <= 3 {
+		return sign + str
+	}
+
+	result := []byte(sign)
+	for i, digit := range str {
+		if i > 0 && (len(str)-i)%3 == 0 {
+			result = append(result, ',')
+		}
+		result = append(result, byte(digit))
+	}
+	return string(result)
+}
+
+// runContext returns the context counting runs under and its cancel
+// function. Ctrl-C stops counting and reports what was counted so far; a
+// second Ctrl-C kills the process as usual. A timeout > 0 stops counting
+// once it has passed.
+func runContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	// The goroutine only ever sees sigCtx, which is never reassigned.
+	sigCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	go func() {
+		<-sigCtx.Done()
+		stop()
+	}()
+	if timeout <= 0 {
+		return sigCtx, stop
+	}
+	ctx, cancel := context.WithTimeout(sigCtx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
+
+// effectiveMinLen returns the -min-len in effect: minLen, raised to 2 by
+// -no-single. A larger -min-len already leaves out single letters.
+func effectiveMinLen(minLen int, noSingle bool) int {
+	if noSingle {
+		return max(minLen, 2)
+	}
+	return minLen
+}
+
+// topLimit clamps a requested top-N to the number of available words.
+// A limit of 0 means "all words".
+func topLimit(n, available int) int {
+	if n == 0 || n > available {
+		return available
+	}
+	return n
+}
+
+// displayName returns a human-readable name for the input argument.
+func displayName(filename string) string {
+	if filename == stdinName {
+		return "<stdin>"
+	}
+	return filename
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: ./wordcount_go [flags] [filename|- ...]\n\n")
+	fmt.Fprintf(os.Stderr, "Use \"-\" as the filename to read from standard input. Multiple files\n")
+	fmt.Fprintf(os.Stderr, "are counted together unless -separate is given.\n\n")
+	fmt.Fprintf(os.Stderr, "Exit status is 0 on success, 1 on any error and %d when -fail-empty is\n", exitNoWords)
+	fmt.Fprintf(os.Stderr, "given and no words were counted.\n\n")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	top := flag.Int("top", 0, "number of words to print and write (0 = all; default 10 on console, 100 in file; see -top-console and -top-file)")
+	topConsole := flag.Int("top-console", 0, "number of words to print on the console, overriding -top (0 = all)")
+	topFile := flag.Int("top-file", 0, "number of words to write to the results file, overriding -top (0 = all)")
+	compact := flag.Bool("compact", false, "with -format text, list words as unpadded \"word count\" lines, much smaller for -full dumps")
+	force := flag.Bool("force", false, "count input even if its first bytes look like binary data rather than text")
+	appendTo := flag.String("append", "", "add the counts to those saved in this results file and rewrite it in its own format (created with -format if it does not exist)")
+	collateLocale := flag.String("collate", "", "break ties between words (or with -sort alpha, order them) in the dictionary order of `LOCALE` (e.g. en, de, sv) instead of by bytes")
+	full := flag.Bool("full", false, "write every word to the results file, whatever -top is set to")
+	emojiMode := flag.Bool("emoji", false, "also count each emoji, skin-tone variant, ZWJ sequence and flag as a word (implies -unicode)")
+	unicodeMode := flag.Bool("unicode", false, "treat any Unicode letter as a word character (slower than the ASCII default)")
+	contractions := flag.Bool("contractions", false, "keep apostrophes between letters as part of a word (don't, it's)")
+	hyphens := flag.Bool("hyphens", false, "keep hyphens between letters as part of a word (well-being)")
+	wordChars := flag.String("wordchars", "", "use the ASCII characters and ranges in `SET` (e.g. \"a-z0-9'-\") as word characters instead of letters")
+	delimiter := flag.String("delimiter", "", "count pre-tokenized input: split only on the ASCII `CHAR` (e.g. , or \\t) and count each token verbatim, lowercased unless -case-sensitive")
+	recordSep := flag.String("record-sep", "", "promise that no word crosses the ASCII `CHAR` (e.g. \\n for line-oriented logs), so input is only ever cut just after it")
+	lines := flag.Bool("lines", false, "count each line as one token, like -delimiter \\n")
+	digits := flag.Bool("digits", false, "treat digits 0-9 as word characters (error404, 2023)")
+	order := flag.String("sort", sortCount, "order words by count (descending, ties alphabetical), count-asc, alpha, length or insertion (first appearance, counting sequentially)")
+	format := flag.String("format", formatText, "results file format: text, json, jsonl, csv or tsv")
+	output := flag.String("o", "", "write results to `PATH` instead of <input>_go_results.<ext> (- = stdout)")
+	parallel := flag.Int("parallel", runtime.GOMAXPROCS(0), "number of goroutines counting in parallel (1 = sequential)")
+	merge := flag.Bool("merge", false, "combine previously written results files (text, json, jsonl, csv or tsv) instead of counting text")
+	compare := flag.String("compare", "", "compare the inputs with `FILE2`, listing the words whose relative frequencies differ most in each direction")
+	matrix := flag.Bool("matrix", false, "count each input file separately and write a CSV term-document matrix: one row per word (see -min-count), one column per file")
+	tfidfMode := flag.Bool("tfidf", false, "treat each input file as a document and list the words with the highest TF-IDF scores in each")
+	filesFrom := flag.String("files-from", "", "also count the files listed one per line in `FILE` (# starts a comment); missing ones are skipped with a warning")
+	recursive := flag.Bool("recursive", false, "count the .txt and .txt.gz files below any directory given as an input")
+	strict := flag.Bool("strict", false, "with -files-from, fail instead of skipping listed files that do not exist")
+	separate := flag.Bool("separate", false, "count each input file on its own and write one results file per input")
+	stopWordsFile := flag.String("stopwords", "", "skip the words listed one per line in `FILE`")
+	dictFile := flag.String("dict", "", "after counting, list the words that are not in the dictionary `FILE` (one word per line), most frequent first")
+	onlyFile := flag.String("only", "", "count only the words listed one per line in `FILE`, skipping all others")
+	stopWordsDefault := flag.Bool("stopwords-default", false, "skip words from the built-in English stop-word list")
+	verbose := flag.Bool("v", false, "log each input file as it is counted, on stderr")
+	debug := flag.Bool("vv", false, "like -v, and also log how the input is cut into blocks and where partial words are carried over")
+	quiet := flag.Bool("quiet", false, "print nothing but errors; only the results file (or stdout with -o -) is written")
+	flag.BoolVar(quiet, "only-file", false, "same as -quiet")
+	noFile := flag.Bool("no-output-file", false, "print the console summary without writing a results file")
+	failEmpty := flag.Bool("fail-empty", false, "exit with status 2 if no words were counted (e.g. empty or binary input)")
+	mmapInput := flag.Bool("mmap", false, "memory-map input files instead of reading them (not for stdin, gzip input, -encoding, -normalize or -progress)")
+	progress := flag.Bool("progress", false, "report progress and an ETA on stderr while counting")
+	encoding := flag.String("encoding", encodingUTF8, "decode input from utf8, latin1 or windows-1252; legacy encodings enable -unicode")
+	normalize := flag.String("normalize", "", "normalize text to Unicode `FORM` nfc or nfkc before counting, so differently composed accents match (enables -unicode)")
+	gzipInput := flag.Bool("gzip", false, "decompress input with gzip (automatic for files ending in .gz)")
+	caseSensitive := flag.Bool("case-sensitive", false, "count words with different letter case separately")
+	display := flag.String("display", displayCounted, "write listed words as `MODE`: counted, lower (even with -case-sensitive), first (the first spelling in the input, counting sequentially) or common (the most frequent spelling)")
+	lowercaseOutput := flag.Bool("lowercase-output", false, "same as -display lower")
+	maxUnique := flag.Int("max-unique", 0, "bound memory by keeping at most `N` unique words, evicting rare ones (approximate; 0 = unbounded)")
+	stable := flag.Bool("stable", false, "omit the generation and execution times from the results file so runs on the same input produce identical files")
+	precision := flag.Int("precision", 2, "print percentages with `N` decimal places")
+	prefix := flag.String("prefix", "", "list only the words starting with `STR` (case-folded like the words)")
+	suffix := flag.String("suffix", "", "list only the words ending with `STR` (case-folded like the words)")
+	match := flag.String("match", "", "count only the words matching the regular expression `RE` (slower; see -exclude)")
+	exclude := flag.String("exclude", "", "skip the words matching the regular expression `RE`, e.g. '^[0-9]+$' with -digits")
+	minCount := flag.Int("min-count", 0, "leave out words counted fewer than `N` times (they still count towards the total)")
+	bottom := flag.Int("bottom", 0, "also show the `N` least frequent words, rarest first")
+	coverageList := flag.String("coverage", "", "report how many unique words cover each of the comma-separated `PERCENTAGES` of all words (e.g. 50,80,90)")
+	perLength := flag.Int("top-per-length", 0, "also show the `N` most frequent words of each length (in characters with -unicode)")
+	bandList := flag.String("bands", "", "also write the words into one results file per frequency band split at the comma-separated `COUNTS` (e.g. 100,10)")
+	phonetic := flag.String("phonetic", "", "group words that sound alike by `ALGORITHM` (soundex), shown under their most common spelling")
+	stem := flag.String("stem", "", "group words by their stems from `ALGORITHM` (porter), shown under their most common form (ASCII letters only)")
+	freqHist := flag.Bool("freq-hist", false, "report how many unique words occur once, twice, 3-10 times, 11-100 times and so on")
+	lengths := flag.Bool("lengths", false, "report the distribution of word lengths (in characters with -unicode)")
+	minLen := flag.Int("min-len", 0, "skip words shorter than `N` bytes (characters with -unicode)")
+	noSingle := flag.Bool("no-single", false, "skip single-letter words such as \"a\" and \"I\"; same as -min-len 2")
+	maxLen := flag.Int("max-len", 0, "skip words longer than `N` bytes, or characters with -unicode (0 = keep them, truncated to 100)")
+	ngram := flag.Int("ngram", 1, "count sequences of `N` consecutive words instead of single words (always sequential)")
+	cooccur := flag.Int("cooccur", 0, "count pairs of different words at most `K` words apart, listed as \"wordA wordB\", instead of single words (always sequential)")
+	bufferSize := 0
+	flag.Func("buffer-size", "read input in chunks of `BYTES` when counting sequentially (suffixes K, M, G; default 64K)", func(s string) error {
+		n, err := parseByteSize(s)
+		if err != nil {
+			return err
+		}
+		if n < wordfreq.MaxWordLength {
+			return fmt.Errorf("must be at least %d bytes", wordfreq.MaxWordLength)
+		}
+		bufferSize = n
+		return nil
+	})
+	limitBytes := 0
+	flag.Func("limit-bytes", "count only about the first `BYTES` of each input (suffixes K, M, G), finishing the word running there, for a quick preview", func(s string) error {
+		n, err := parseByteSize(s)
+		if err != nil {
+			return err
+		}
+		if n < 1 {
+			return fmt.Errorf("must be at least 1 byte")
+		}
+		limitBytes = n
+		return nil
+	})
+	textStats := flag.Bool("wc", false, "also count lines, bytes and characters (UTF-8 characters with -unicode), like wc")
+	mapSize := flag.Int("map-size", 0, "start the count store with room for about `N` unique words, saving rehashes on big vocabularies (0 = estimated from the file size, at least 16384)")
+	hashName := flag.String("hash", wordfreq.HashFNV.String(), "count with the fnv-table, map, xxhash or sharded data structure (same results, different speed)")
+	sample := flag.Float64("sample", 1, "count only a deterministic, hash-chosen `RATE` (0-1] of distinct words for quick approximate shares")
+	repeat := flag.Int("repeat", 1, "count the input `N` times and report the min, median and mean execution time (results are from the last run)")
+	compress := flag.Bool("compress", false, "gzip the results file, adding .gz to its name unless it already ends in .gz")
+	outputEncoding := flag.String("output-encoding", encodingUTF8, "write the results file in utf8, latin1, windows-1252, shift-jis, euc-jp, euc-kr, gbk or big5; characters it lacks become its substitute byte")
+	countOnly := flag.Bool("count-only", false, "only count the total number of words, like wc -w, printing it on stdout; no word is kept, so it is fast and small")
+	concord := flag.String("concordance", "", "instead of counting, list the lines on which `WORD` occurs (matched as counted, e.g. case-folded) as \"line: text\" on stdout or in -o PATH")
+	replMode := flag.Bool("repl", false, "after counting, read word lookups and commands (top N, prefix P) from stdin until quit")
+	baseline := flag.String("baseline", "", "compare the time and memory of this run with a -metrics JSON `FILE` saved earlier")
+	maxSlowdown := flag.Float64("max-slowdown", 0, "with -baseline, exit with status 1 if the run is more than `PCT` percent slower than the baseline (0 = never)")
+	metrics := flag.String("metrics", "", "also write the statistics (sizes, word totals, time, memory, Go version) as JSON to `FILE` (- = stdout)")
+	timeout := flag.Duration("timeout", 0, "stop counting after `DURATION` (e.g. 30s, 5m) and report the partial results")
+	flag.Parse()
+
+	cfg := config{
+		opts: wordfreq.Options{
+			Unicode:       *unicodeMode || *emojiMode,
+			Emoji:         *emojiMode,
+			Contractions:  *contractions,
+			Hyphens:       *hyphens,
+			Digits:        *digits,
+			MinLength:     *minLen,
+			MaxLength:     *maxLen,
+			CaseSensitive: *caseSensitive,
+			MaxUnique:     *maxUnique,
+			Lengths:       *lengths,
+			NGram:         *ngram,
+			Cooccur:       *cooccur,
+			BufferSize:    bufferSize,
+			LimitBytes:    int64(limitBytes),
+			TextStats:     *textStats,
+			Sample:        *sample,
+			MapSize:       *mapSize,
+		},
+		workers:    *parallel,
+		display:    *display,
+		consoleTop: defaultConsoleTop,
+		bottom:     *bottom,
+		minCount:   *minCount,
+		perLength:  *perLength,
+		freqHist:   *freqHist,
+		fileTop:    defaultFileTop,
+		format:     *format,
+		order:      *order,
+		precision:  *precision,
+		stable:     *stable,
+		output:     *output,
+		gzip:       *gzipInput,
+		progress:   *progress,
+		mmap:       *mmapInput,
+		failEmpty:  *failEmpty,
+		quiet:      *quiet,
+		noFile:     *noFile,
+		repeat:     *repeat,
+		metrics:    *metrics,
+		compress:   *compress,
+		repl:       *replMode,
+		countOnly:  *countOnly,
+		compact:    *compact,
+		force:      *force,
+		log:        newLogger(*verbose, *debug, *quiet),
+	}
+	cfg.opts.Logger = cfg.log
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["top"] {
+		cfg.consoleTop, cfg.fileTop = *top, *top
+	}
+	if set["top-console"] {
+		cfg.consoleTop = *topConsole
+	}
+	if set["top-file"] {
+		cfg.fileTop = *topFile
+	}
+	if *full {
+		cfg.fileTop = 0
+	}
+	if *top < 0 || *topConsole < 0 || *topFile < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -top, -top-console and -top-file must be >= 0\n")
+		os.Exit(1)
+	}
+	if *bottom < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -bottom must be >= 0, got %d\n", *bottom)
+		os.Exit(1)
+	}
+	if *minCount < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -min-count must be >= 0, got %d\n", *minCount)
+		os.Exit(1)
+	}
+	if *perLength < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -top-per-length must be >= 0, got %d\n", *perLength)
+		os.Exit(1)
+	}
+	if *precision < 0 || *precision > 10 {
+		fmt.Fprintf(os.Stderr, "Error: -precision must be between 0 and 10, got %d\n", *precision)
+		os.Exit(1)
+	}
+	if *minLen < 0 || *maxLen < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -min-len and -max-len must be >= 0\n")
+		os.Exit(1)
+	}
+	*minLen = effectiveMinLen(*minLen, *noSingle)
+	cfg.opts.MinLength = *minLen
+	if *maxLen > 0 && *minLen > *maxLen {
+		fmt.Fprintf(os.Stderr, "Error: -min-len (%d) is greater than -max-len (%d)\n", *minLen, *maxLen)
+		os.Exit(1)
+	}
+	if *mapSize < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -map-size must be >= 0, got %d\n", *mapSize)
+		os.Exit(1)
+	}
+	if *maxUnique < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -max-unique must be >= 0, got %d\n", *maxUnique)
+		os.Exit(1)
+	}
+	if *ngram < 1 {
+		fmt.Fprintf(os.Stderr, "Error: -ngram must be >= 1, got %d\n", *ngram)
+		os.Exit(1)
+	}
+	if *cooccur < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -cooccur must be >= 0, got %d\n", *cooccur)
+		os.Exit(1)
+	}
+	if *coverageList != "" {
+		thresholds, err := parseCoverage(*coverageList)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -coverage: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.coverage = thresholds
+	}
+	switch *phonetic {
+	case "":
+	case "soundex":
+		cfg.groupKey = wordfreq.SoundexCode
+		cfg.notes = append(cfg.notes, "Words grouped by Soundex code under their most common spelling")
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown -phonetic %q (want soundex)\n", *phonetic)
+		os.Exit(1)
+	}
+	switch *stem {
+	case "":
+	case "porter":
+		if cfg.groupKey != nil {
+			fmt.Fprintf(os.Stderr, "Error: -stem cannot be combined with -phonetic\n")
+			os.Exit(1)
+		}
+		if *unicodeMode || *encoding != encodingUTF8 || *normalize != "" || *contractions || *hyphens ||
+			*digits || *wordChars != "" || *caseSensitive || *ngram > 1 {
+			fmt.Fprintf(os.Stderr, "Error: -stem porter only works on lowercase ASCII letters; it cannot be combined with "+
+				"-unicode, -encoding, -normalize, -contractions, -hyphens, -digits, -wordchars, -case-sensitive or -ngram\n")
+			os.Exit(1)
+		}
+		cfg.groupKey = wordfreq.Stem
+		cfg.notes = append(cfg.notes, "Words grouped by Porter stem under their most common form")
+	default:
+		fmt.Fprintf(os.Stderr, "Error: unknown -stem %q (want porter)\n", *stem)
+		os.Exit(1)
+	}
+	if *bandList != "" {
+		thresholds, err := parseBands(*bandList)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -bands: %v\n", err)
+			os.Exit(1)
+		}
+		if *output == stdinName || *noFile {
+			fmt.Fprintf(os.Stderr, "Error: -bands writes files and cannot be combined with -o - or -no-output-file\n")
+			os.Exit(1)
+		}
+		cfg.bands = thresholds
+	}
+	if *lines {
+		if *delimiter != "" && *delimiter != `\n` {
+			fmt.Fprintf(os.Stderr, "Error: -lines cannot be combined with -delimiter\n")
+			os.Exit(1)
+		}
+		*delimiter = `\n`
+	}
+	if *delimiter != "" {
+		delim, err := parseDelimiter(*delimiter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -delimiter: %v\n", err)
+			os.Exit(1)
+		}
+		if *contractions || *hyphens || *digits || *wordChars != "" || *stem != "" {
+			fmt.Fprintf(os.Stderr, "Error: -delimiter and -lines cannot be combined with -contractions, -hyphens, -digits, -wordchars or -stem\n")
+			os.Exit(1)
+		}
+		cfg.opts.Delimiter = delim
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Pre-tokenized input: tokens split on %q", delim))
+	}
+	if *recordSep != "" {
+		sep, err := parseDelimiter(*recordSep)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -record-sep: %v\n", err)
+			os.Exit(1)
+		}
+		if sep >= '0' && sep <= '9' || sep|0x20 >= 'a' && sep|0x20 <= 'z' {
+			fmt.Fprintf(os.Stderr, "Error: -record-sep %q is a letter or digit, which words are made of\n", sep)
+			os.Exit(1)
+		}
+		cfg.opts.RecordSep = sep
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Input split only on record separator %q", sep))
+	}
+	if *wordChars != "" {
+		chars, err := wordfreq.ParseWordChars(*wordChars)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -wordchars: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.opts.WordChars = chars
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Word characters: %q", *wordChars))
+	}
+	charset, ok := encodings[strings.ToLower(*encoding)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown -encoding %q (want %s)\n", *encoding, sortedKeys(encodings))
+		os.Exit(1)
+	}
+	if charset != nil {
+		// Decoded text is UTF-8, and its non-ASCII letters are only word
+		// characters on the Unicode path.
+		cfg.charset = charset
+		cfg.opts.Unicode = true
+		cfg.notes = append(cfg.notes, "Input decoded from "+strings.ToLower(*encoding))
+	}
+	outCharset, ok := outputEncodings[strings.ToLower(*outputEncoding)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown -output-encoding %q (want %s)\n", *outputEncoding, sortedKeys(outputEncodings))
+		os.Exit(1)
+	}
+	if outCharset != nil {
+		cfg.outCharset = outCharset
+		cfg.notes = append(cfg.notes, "Results encoded in "+strings.ToLower(*outputEncoding))
+	}
+	if *normalize != "" {
+		form := strings.ToLower(*normalize)
+		if _, ok := normForms[form]; !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown -normalize %q (want %s)\n", *normalize, sortedKeys(normForms))
+			os.Exit(1)
+		}
+		cfg.normalize = form
+		cfg.opts.Unicode = true
+		cfg.notes = append(cfg.notes, "Text normalized to Unicode "+strings.ToUpper(form))
+	}
+	hash, err := wordfreq.ParseHash(*hashName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: -hash: %v\n", err)
+		os.Exit(1)
+	}
+	cfg.opts.Hash = hash
+	if *timeout < 0 {
+		fmt.Fprintf(os.Stderr, "Error: -timeout must be >= 0, got %v\n", *timeout)
+		os.Exit(1)
+	}
+	if *sample <= 0 || *sample > 1 {
+		fmt.Fprintf(os.Stderr, "Error: -sample must be greater than 0 and at most 1, got %g\n", *sample)
+		os.Exit(1)
+	}
+	if *sample < 1 {
+		if *ngram > 1 {
+			fmt.Fprintf(os.Stderr, "Error: -sample cannot be combined with -ngram\n")
+			os.Exit(1)
+		}
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Sampled counts: only words in a %g%% hash sample were counted; shares of the total are estimates", *sample*100))
+	}
+	if *noFile && (*quiet || *output != "") {
+		fmt.Fprintf(os.Stderr, "Error: -no-output-file cannot be combined with -quiet, -only-file or -o\n")
+		os.Exit(1)
+	}
+	if *repeat < 1 {
+		fmt.Fprintf(os.Stderr, "Error: -repeat must be >= 1, got %d\n", *repeat)
+		os.Exit(1)
+	}
+	if *parallel < 1 {
+		fmt.Fprintf(os.Stderr, "Error: -parallel must be >= 1, got %d\n", *parallel)
+		os.Exit(1)
+	}
+	if _, ok := sortOrders[*order]; !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown -sort %q (want count, count-asc, alpha, length or insertion)\n", *order)
+		os.Exit(1)
+	}
+	if *order == sortInsertion {
+		if *merge || *appendTo != "" || *phonetic != "" || *stem != "" || *collateLocale != "" {
+			fmt.Fprintf(os.Stderr, "Error: -sort insertion cannot be combined with -merge, -append, -phonetic, -stem or -collate\n")
+			os.Exit(1)
+		}
+		// First appearances are only known when the input is read in order.
+		cfg.opts.FirstSeen = true
+		cfg.workers = 1
+	}
+	if *collateLocale != "" {
+		tag, err := language.Parse(*collateLocale)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -collate: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.collator = collate.New(tag)
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Words sorted in %s dictionary order", tag))
+	}
+	if *compact && *format != formatText {
+		fmt.Fprintf(os.Stderr, "Error: -compact only applies to -format text\n")
+		os.Exit(1)
+	}
+	if *freqHist && *format != formatText && *format != formatJSON {
+		fmt.Fprintf(os.Stderr, "Error: -freq-hist is only written with -format text or json\n")
+		os.Exit(1)
+	}
+	if cfg.groupKey != nil && *format != formatText && *format != formatJSON {
+		fmt.Fprintf(os.Stderr, "Error: -phonetic and -stem list their grouped variants only with -format text or json\n")
+		os.Exit(1)
+	}
+	if _, ok := formatExtensions[*format]; !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown -format %q (want text, json, jsonl, csv or tsv)\n", *format)
+		os.Exit(1)
+	}
+
+	if *lowercaseOutput {
+		if *display != displayCounted && *display != displayLower {
+			fmt.Fprintf(os.Stderr, "Error: -lowercase-output cannot be combined with -display %s\n", *display)
+			os.Exit(1)
+		}
+		cfg.display = displayLower
+	}
+	spelling, ok := displayModes[cfg.display]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown -display %q (want counted, lower, first or common)\n", cfg.display)
+		os.Exit(1)
+	}
+	if cfg.display != displayCounted {
+		if *merge || cfg.groupKey != nil || (spelling != wordfreq.SpellingNone && *ngram > 1) {
+			fmt.Fprintf(os.Stderr, "Error: -display %s cannot be combined with -merge, -phonetic or -stem, "+
+				"nor with -ngram unless lower\n", cfg.display)
+			os.Exit(1)
+		}
+		cfg.opts.Spelling = spelling
+		if spelling == wordfreq.SpellingFirst {
+			// The first spelling is only known when the input is read in order.
+			cfg.workers = 1
+		}
+		switch cfg.display {
+		case displayLower:
+			cfg.notes = append(cfg.notes, "Words shown in lowercase")
+		case displayFirst:
+			cfg.notes = append(cfg.notes, "Words shown as first spelled in the input")
+		case displayCommon:
+			cfg.notes = append(cfg.notes, "Words shown as most often spelled in the input")
+		}
+	}
+	if *ngram > 1 {
+		// Grams span block boundaries, so the library counts sequentially.
+		cfg.workers = 1
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Counting %d-word sequences (n-grams)", *ngram))
+	}
+	if *cooccur > 0 {
+		if *ngram > 1 || *sample < 1 || cfg.groupKey != nil || spelling != wordfreq.SpellingNone {
+			fmt.Fprintf(os.Stderr, "Error: -cooccur cannot be combined with -ngram, -sample, -phonetic or -stem, "+
+				"nor with -display unless lower\n")
+			os.Exit(1)
+		}
+		// Like grams, the window spans block boundaries.
+		cfg.workers = 1
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Counting pairs of words at most %d apart (co-occurrences)", *cooccur))
+	}
+	switch *order {
+	case sortCount:
+	case sortInsertion:
+		cfg.notes = append(cfg.notes, "Words listed in order of first appearance")
+	default:
+		cfg.notes = append(cfg.notes, "Words sorted by "+*order)
+	}
+	if *caseSensitive {
+		cfg.notes = append(cfg.notes, "Counts are case-sensitive")
+	}
+	if *match != "" {
+		re, err := regexp.Compile(*match)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -match: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.opts.Match = re
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Only words matching %q are counted", *match))
+	}
+	if *exclude != "" {
+		re, err := regexp.Compile(*exclude)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -exclude: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.opts.Exclude = re
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Words matching %q are excluded from all counts", *exclude))
+	}
+	cfg.prefix, cfg.suffix = foldCase(cfg.opts, *prefix), foldCase(cfg.opts, *suffix)
+	if cfg.prefix != "" {
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Only words starting with %q are listed", cfg.prefix))
+	}
+	if cfg.suffix != "" {
+		cfg.notes = append(cfg.notes, fmt.Sprintf("Only words ending with %q are listed", cfg.suffix))
+	}
+	if err := loadStopWords(&cfg, *stopWordsFile, *stopWordsDefault); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading stop words: %v\n", err)
+		os.Exit(1)
+	}
+	if err := loadAllowList(&cfg, *onlyFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading -only words: %v\n", err)
+		os.Exit(1)
+	}
+	if err := loadDictionary(&cfg, *dictFile); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading -dict words: %v\n", err)
+		os.Exit(1)
+	}
+
+	filenames := flag.Args()
+	if *filesFrom != "" {
+		listed, err := readManifest(*filesFrom)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -files-from: %v\n", err)
+			os.Exit(1)
+		}
+		for _, filename := range listed {
+			if _, err := os.Stat(filename); err != nil && filename != stdinName {
+				if *strict {
+					fmt.Fprintf(os.Stderr, "Error: -files-from %s: %v\n", *filesFrom, err)
+					os.Exit(1)
+				}
+				cfg.log.Warn("skipping missing input", "file", filename, "err", err)
+				continue
+			}
+			filenames = append(filenames, filename)
+		}
+		if len(filenames) == 0 {
+			fmt.Fprintf(os.Stderr, "Error: no input files found in %s\n", *filesFrom)
+			os.Exit(1)
+		}
+	}
+	if len(filenames) == 0 {
+		filenames = []string{"book.txt"}
+	}
+
+	for _, filename := range filenames {
+		if _, err := os.Stat(filename); filename != stdinName && os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Error: File '%s' not found\n", filename)
+			fmt.Fprintln(os.Stderr, "Usage: ./wordcount_go [flags] [filename|- ...]")
+			fmt.Fprintln(os.Stderr, "\nTo create a test file:")
+			fmt.Fprintln(os.Stderr, "curl https://www.gutenberg.org/files/2701/2701-0.txt -o book.txt")
+			os.Exit(1)
+		}
+	}
+	filenames, err = expandDirs(filenames, *recursive)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *repeat > 1 && slices.Contains(filenames, stdinName) {
+		fmt.Fprintf(os.Stderr, "Error: -repeat cannot read standard input more than once\n")
+		os.Exit(1)
+	}
+
+	if cfg.countOnly {
+		if *output != "" || *metrics == stdinName || *bottom > 0 || cfg.coverage != nil || *perLength > 0 || cfg.bands != nil ||
+			*freqHist || cfg.groupKey != nil || cfg.display != displayCounted || cfg.repl || *compare != "" || *matrix ||
+			*tfidfMode || *merge || cfg.prefix != "" || cfg.suffix != "" || *minCount > 1 {
+			fmt.Fprintf(os.Stderr, "Error: -count-only keeps no words, so it cannot be combined with options that list, "+
+				"filter or check them (-o, -metrics -, -bottom, -coverage, -top-per-length, -bands, -freq-hist, -phonetic, "+
+				"-stem, -display, -repl, -compare, -matrix, -tfidf, -merge, -prefix, -suffix or -min-count)\n")
+			os.Exit(1)
+		}
+		cfg.opts.CountOnly = true
+	}
+	if cfg.repl {
+		if slices.Contains(filenames, stdinName) || *separate || *merge || *compare != "" || *matrix || *tfidfMode ||
+			*quiet || cfg.display != displayCounted {
+			fmt.Fprintf(os.Stderr, "Error: -repl reads stdin, so it cannot count it; nor can it be combined with "+
+				"-separate, -merge, -compare, -matrix, -tfidf, -quiet or -display\n")
+			os.Exit(1)
+		}
+		cfg.keepAll = true
+	}
+	if *baseline != "" {
+		if *separate {
+			fmt.Fprintf(os.Stderr, "Error: -baseline cannot be combined with -separate\n")
+			os.Exit(1)
+		}
+		base, err := readMetrics(*baseline)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -baseline: %v\n", err)
+			os.Exit(1)
+		}
+		cfg.baseline, cfg.baseName = base, *baseline
+	}
+	if *maxSlowdown < 0 || (*maxSlowdown > 0 && *baseline == "") {
+		fmt.Fprintf(os.Stderr, "Error: -max-slowdown must be a positive percentage given with -baseline\n")
+		os.Exit(1)
+	}
+	cfg.maxSlow = *maxSlowdown
+	if *metrics != "" && (*separate || (*metrics == stdinName && *output == stdinName)) {
+		fmt.Fprintf(os.Stderr, "Error: -metrics cannot be combined with -separate, nor written to stdout along with -o -\n")
+		os.Exit(1)
+	}
+
+	if *separate && *output != "" && len(filenames) > 1 {
+		fmt.Fprintf(os.Stderr, "Error: -o cannot be combined with -separate for multiple inputs\n")
+		os.Exit(1)
+	}
+
+	if *appendTo != "" {
+		if *merge || *separate || *noFile || *output != "" || *compare != "" || *matrix || *tfidfMode ||
+			*countOnly || *compress || cfg.bands != nil || cfg.outCharset != nil {
+			fmt.Fprintf(os.Stderr, "Error: -append cannot be combined with -merge, -separate, -no-output-file, -o, "+
+				"-compare, -matrix, -tfidf, -count-only, -compress, -bands or -output-encoding\n")
+			os.Exit(1)
+		}
+		if filters := appendFilters(cfg); filters != "" {
+			fmt.Fprintf(os.Stderr, "Error: -append rewrites every word of its file, so it cannot be combined with %s, "+
+				"which would drop saved words from it for good\n", filters)
+			os.Exit(1)
+		}
+		if err := loadAppend(&cfg, *appendTo); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading -append file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, cancel := runContext(*timeout)
+	defer cancel()
+
+	if *compare != "" {
+		if *merge || *separate || *repeat > 1 || cfg.bands != nil {
+			fmt.Fprintf(os.Stderr, "Error: -compare cannot be combined with -merge, -separate, -repeat or -bands\n")
+			os.Exit(1)
+		}
+		if _, err := os.Stat(*compare); *compare != stdinName && err != nil {
+			fmt.Fprintf(os.Stderr, "Error: -compare: %v\n", err)
+			os.Exit(1)
+		}
+		err := runCompare(ctx, cfg, filenames, *compare)
+		if errors.Is(err, errNoWords) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitNoWords)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error comparing files: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *concord != "" {
+		if *merge || *separate || *compare != "" || *matrix || *tfidfMode || *countOnly || cfg.repl ||
+			*repeat > 1 || cfg.bands != nil || *appendTo != "" || *ngram > 1 || *cooccur > 0 {
+			fmt.Fprintf(os.Stderr, "Error: -concordance cannot be combined with -merge, -separate, -compare, -matrix, -tfidf, "+
+				"-count-only, -repl, -repeat, -bands, -append, -ngram or -cooccur\n")
+			os.Exit(1)
+		}
+		if err := runConcordance(ctx, cfg, filenames, *concord); err != nil {
+			fmt.Fprintf(os.Stderr, "Error building concordance: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *tfidfMode {
+		if *matrix || *merge || *separate || *repeat > 1 || cfg.bands != nil || cfg.display != displayCounted {
+			fmt.Fprintf(os.Stderr, "Error: -tfidf cannot be combined with -matrix, -merge, -separate, -repeat, -bands or -display\n")
+			os.Exit(1)
+		}
+		if len(filenames) < 2 {
+			fmt.Fprintf(os.Stderr, "Error: -tfidf needs at least two input files\n")
+			os.Exit(1)
+		}
+		if err := runTFIDF(ctx, cfg, filenames); err != nil {
+			fmt.Fprintf(os.Stderr, "Error scoring documents: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *matrix {
+		if *merge || *separate || *repeat > 1 || cfg.bands != nil || cfg.display != displayCounted {
+			fmt.Fprintf(os.Stderr, "Error: -matrix cannot be combined with -merge, -separate, -repeat, -bands or -display\n")
+			os.Exit(1)
+		}
+		if len(filenames) < 2 {
+			fmt.Fprintf(os.Stderr, "Error: -matrix needs at least two input files\n")
+			os.Exit(1)
+		}
+		err := runMatrix(ctx, cfg, filenames)
+		if errors.Is(err, errNoWords) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitNoWords)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error building matrix: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *merge {
+		if *separate || *repeat > 1 {
+			fmt.Fprintf(os.Stderr, "Error: -merge cannot be combined with -separate or -repeat\n")
+			os.Exit(1)
+		}
+		err := runMerge(cfg, filenames)
+		if errors.Is(err, errNoWords) {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(exitNoWords)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error merging results: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *separate {
+		empty := false
+		for _, filename := range filenames {
+			err := run(ctx, cfg, []string{filename})
+			if errors.Is(err, errNoWords) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				empty = true
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error processing file: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if empty {
+			os.Exit(exitNoWords)
+		}
+		return
+	}
+	err = run(ctx, cfg, filenames)
+	if errors.Is(err, errNoWords) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitNoWords)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error processing file: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadStopWords builds cfg.opts.StopWords from a stop-word file and/or the
+// built-in list. Words are normalized with the same options used for
+// counting so they match the counted keys.
+func loadStopWords(cfg *config, filename string, builtin bool) error {
+	if filename == "" && !builtin {
+		return nil
+	}
+
+	words := make(map[string]struct{})
+	var sources []string
+	if builtin {
+		set, err := wordfreq.ReadWordSet(normalizeText(*cfg, strings.NewReader(defaultStopWords)), cfg.opts)
+		if err != nil {
+			return err
+		}
+		for w := range set {
+			words[w] = struct{}{}
+		}
+		sources = append(sources, "built-in English list")
+	}
+	if filename != "" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		set, err := wordfreq.ReadWordSet(normalizeText(*cfg, file), cfg.opts)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		for w := range set {
+			words[w] = struct{}{}
+		}
+		sources = append(sources, filename)
+	}
+
+	cfg.opts.StopWords = words
+	cfg.notes = append(cfg.notes, fmt.Sprintf("Stop words excluded from all counts: %s (%d words)",
+		strings.Join(sources, ", "), len(words)))
+	return nil
+}
+
+// loadAllowList builds cfg.opts.OnlyWords from the -only file, normalizing
+// its words like loadStopWords does.
+func loadAllowList(cfg *config, filename string) error {
+	if filename == "" {
+		return nil
+	}
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	words, err := wordfreq.ReadWordSet(normalizeText(*cfg, file), cfg.opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+	cfg.opts.OnlyWords = words
+	cfg.notes = append(cfg.notes, fmt.Sprintf("Only words listed in %s were counted (%d words)", filename, len(words)))
+	return nil
+}
+
+// inputSize describes how much input a file provided.
+type inputSize struct {
+	bytes      int64 // size on disk, or bytes read from stdin
+	decoded    int64 // bytes seen by the counter after decompression
+	compressed bool
+}
+
+// isGzip reports whether filename should be decompressed before counting.
+func isGzip(cfg config, filename string) bool {
+	return cfg.gzip || strings.HasSuffix(filename, ".gz")
+}
+
+// countFile counts a single input, returning its result and size.
+// Gzip-compressed input is decompressed on the fly.
+// If ctx is done part way, the partial result is returned with ctx's error.
+func countFile(ctx context.Context, cfg config, filename string) (*wordfreq.Result, inputSize, error) {
+	var size inputSize
+	var input io.Reader = os.Stdin
+	if filename != stdinName {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, size, err
+		}
+		defer file.Close()
+		input = file
+		if info, err := file.Stat(); err == nil {
+			size.bytes = info.Size()
+		}
+		// Without -map-size the store is sized from the file.
+		if cfg.opts.MapSize == 0 {
+			cfg.opts.MapSize = storeSize(cfg, size.bytes, isGzip(cfg, filename))
+		}
+		// The mapped bytes are counted in place; a file that cannot be
+		// mapped falls back to buffered reads.
+		if cfg.mmap && !cfg.progress && !isGzip(cfg, filename) && cfg.charset == nil && cfg.normalize == "" {
+			if data, unmap, err := mapFile(file); err == nil {
+				defer unmap()
+				size.decoded = int64(len(data))
+				bom, err := bomLength(cfg, data)
+				if err == nil {
+					err = checkText(cfg, data[bom:min(len(data), bom+sniffSize)])
+				}
+				if err != nil {
+					return nil, size, fmt.Errorf("%s: %w", filename, err)
+				}
+				res, err := wordfreq.TallyBytes(ctx, data[bom:], cfg.opts, cfg.workers)
+				return res, size, err
+			}
+		}
+	}
+	raw := &countingReader{r: input}
+	decoded := raw
+
+	if cfg.progress {
+		stop := startProgress(raw, size.bytes)
+		defer stop()
+	}
+
+	if isGzip(cfg, filename) {
+		gz, err := gzip.NewReader(raw)
+		if err != nil {
+			return nil, size, fmt.Errorf("%s: %w", displayName(filename), err)
+		}
+		defer gz.Close()
+		decoded = &countingReader{r: gz}
+		size.compressed = true
+	}
+
+	text, err := skipBOM(cfg, decoded)
+	if err != nil {
+		return nil, size, fmt.Errorf("%s: %w", displayName(filename), err)
+	}
+	// A read error part way still yields the words counted before it,
+	// which analyze reports as partial results.
+	res, err := wordfreq.TallyContext(ctx, decodeInput(cfg, text), cfg.opts, cfg.workers)
+	if res == nil {
+		return nil, size, fmt.Errorf("%s: %w", displayName(filename), err)
+	}
+
+	if filename == stdinName {
+		size.bytes = raw.bytesRead()
+	}
+	size.decoded = decoded.bytesRead()
+	return res, size, err
+}
+
+// analysis is everything a run computes before any of it is printed, so
+// the counting can be exercised without going through stdout.
+type analysis struct {
+	filenames     []string
+	sorted        []wordfreq.WordCount // in -sort order
+	bottom        []wordfreq.WordCount // least frequent words, if requested
+	totalWords    int64
+	uniqueWords   int
+	lengths       []int64
+	textStats     bool // lines, bytes and chars were counted (-wc)
+	lines         int64
+	bytes         int64
+	chars         int64
+	size          inputSize
+	executionTime float64   // milliseconds, including sorting
+	runTimes      []float64 // executionTime of every -repeat run, in order
+	memory        memoryStats
+	notes         []string
+	variants      map[string][]wordfreq.WordCount // -phonetic or -stem words by group
+}
+
+// memoryStats is how much a run allocated, from the runtime's cumulative
+// counters. Unlike the live heap size, these never shrink when a GC runs
+// part way, so the figures are comparable between runs. heapGrowth is the
+// exception: it is how much larger the live heap ended than it started,
+// which is 0 when a GC left it smaller.
+type memoryStats struct {
+	allocated  uint64 // bytes
+	mallocs    uint64
+	gcs        uint32
+	heapGrowth uint64 // bytes
+}
+
+// memoryDelta returns the allocations made between two MemStats readings.
+func memoryDelta(start, end *runtime.MemStats) memoryStats {
+	return memoryStats{
+		allocated: since(start.TotalAlloc, end.TotalAlloc),
+		mallocs:   since(start.Mallocs, end.Mallocs),
+		gcs:       uint32(since(uint64(start.NumGC), uint64(end.NumGC))),
+		// HeapAlloc goes down as well as up, so it is compared signed.
+		heapGrowth: uint64(max(int64(end.HeapAlloc)-int64(start.HeapAlloc), 0)),
+	}
+}
+
+// since returns end-start, or 0 rather than a wrapped-around value should
+// end be the smaller.
+func since(start, end uint64) uint64 {
+	if end < start {
+		return 0
+	}
+	return end - start
+}
+
+// listLimit returns how many words of the -sort order a run has to list,
+// or 0 when it needs all of them. Only lists that are written count:
+// -full with -no-output-file, say, still sorts just the console's top words.
+func (cfg config) listLimit() int {
+	if cfg.keepAll || cfg.bottom > 0 || cfg.coverage != nil || cfg.perLength > 0 ||
+		cfg.freqHist || cfg.bands != nil || cfg.dict != nil {
+		return 0
+	}
+	console, file := cfg.consoleTop, cfg.fileTop
+	if cfg.quiet {
+		console = 1
+	}
+	if cfg.noFile {
+		file = 1
+	}
+	if console == 0 || file == 0 {
+		return 0
+	}
+	return max(console, file)
+}
+
+// sortCounts orders counts for display with -sort and picks the -bottom
+// words, which are always chosen by frequency. When only the top words are
+// shown, only they are sorted, which saves a slice of the whole vocabulary.
+// firstSeen is the input order of the words for -sort insertion.
+func sortCounts(cfg config, counts map[string]int, firstSeen []string) (sorted, rarest []wordfreq.WordCount) {
+	if cfg.order == sortInsertion {
+		sorted = inOrderSeen(counts, firstSeen, cfg.listLimit())
+	} else {
+		sorted = wordfreq.TopK(counts, cfg.listLimit(), cfg.wordOrder())
+	}
+	if cfg.bottom > 0 {
+		byCount := sorted
+		if cfg.order != sortCount {
+			byCount = wordfreq.TopK(counts, 0, cfg.orderOf(sortCount))
+		}
+		rarest = bottomWords(byCount, cfg.bottom)
+	}
+	return sorted, rarest
+}
+
+// inOrderSeen returns the first limit (0 = all) words of firstSeen that
+// are still in counts, in that order, with their counts.
+func inOrderSeen(counts map[string]int, firstSeen []string, limit int) []wordfreq.WordCount {
+	if limit <= 0 || limit > len(counts) {
+		limit = len(counts)
+	}
+	sorted := make([]wordfreq.WordCount, 0, limit)
+	for _, word := range firstSeen {
+		if len(sorted) == limit {
+			break
+		}
+		if n, ok := counts[word]; ok {
+			sorted = append(sorted, wordfreq.WordCount{Word: word, Count: n})
+		}
+	}
+	return sorted
+}
+
+// analyze counts filenames into a single combined, sorted result.
+// If ctx is done part way, the words counted so far are returned along
+// with an error saying why counting stopped.
+func analyze(ctx context.Context, cfg config, filenames []string) (*analysis, error) {
+	runtime.GC()
+
+	startTime := time.Now()
+	startMem := &runtime.MemStats{}
+	runtime.ReadMemStats(startMem)
+
+	var res wordfreq.Result
+	var total inputSize
+	var stopped error
+	for _, filename := range filenames {
+		cfg.log.Info("counting", "file", displayName(filename))
+		fileStart := time.Now()
+		fileRes, fileSize, err := countFile(ctx, cfg, filename)
+		if fileRes == nil {
+			return nil, err
+		}
+		cfg.log.Info("counted", "file", displayName(filename), "bytes", fileSize.decoded,
+			"words", fileRes.TotalWords, "unique", len(fileRes.Counts), "elapsed", time.Since(fileStart))
+		if res.Counts == nil {
+			res = *fileRes
+		} else {
+			res.Merge(fileRes)
+		}
+		total.bytes += fileSize.bytes
+		total.decoded += fileSize.decoded
+		total.compressed = total.compressed || fileSize.compressed
+		if err != nil {
+			stopped = fmt.Errorf("counting stopped early in %s: %s", displayName(filename), stopReason(err))
+			cfg.log.Warn("partial results", "file", displayName(filename), "err", err)
+			break
+		}
+	}
+
+	if cfg.prior != nil {
+		addPrior(&res, cfg.prior)
+	}
+
+	var variants map[string][]wordfreq.WordCount
+	if cfg.groupKey != nil {
+		res.Counts, variants = groupWords(res.Counts, cfg.groupKey)
+	}
+	dropped := 0
+	if cfg.minCount > 1 {
+		for word, n := range res.Counts {
+			if n < cfg.minCount {
+				delete(res.Counts, word)
+				dropped++
+			}
+		}
+	}
+	if cfg.prefix != "" || cfg.suffix != "" {
+		for word := range res.Counts {
+			if !cfg.hasAffixes(word) {
+				delete(res.Counts, word)
+			}
+		}
+	}
+	sorted, rarest := sortCounts(cfg, res.Counts, res.FirstSeen)
+	displayWords(cfg.display, res.Spellings, sorted, cfg.wordOrder())
+	displayWords(cfg.display, res.Spellings, rarest, cfg.orderOf(sortCountAsc))
+
+	duration := time.Since(startTime)
+
+	endMem := &runtime.MemStats{}
+	runtime.ReadMemStats(endMem)
+
+	notes := cfg.notes
+	if stopped != nil {
+		notes = append(notes, "Partial results: "+stopped.Error())
+	}
+	if cfg.minCount > 1 {
+		notes = append(notes, fmt.Sprintf("%s unique words counted fewer than %d times are left out, but still included in the total",
+			formatNumber(int64(dropped)), cfg.minCount))
+	}
+	if res.Limited {
+		notes = append(notes, fmt.Sprintf("Only a prefix was counted: input stopped after about %s bytes (-limit-bytes)",
+			formatNumber(cfg.opts.LimitBytes)))
+	}
+	if res.Pruned {
+		notes = append(notes, fmt.Sprintf("Approximate counts: vocabulary capped at %s unique words; rare words were evicted and any count may be low by up to %s",
+			formatNumber(int64(cfg.opts.MaxUnique)), formatNumber(int64(res.MaxUndercount))))
+	}
+
+	return &analysis{
+		filenames:     filenames,
+		sorted:        sorted,
+		bottom:        rarest,
+		totalWords:    res.TotalWords,
+		uniqueWords:   len(res.Counts),
+		lengths:       res.Lengths,
+		textStats:     cfg.opts.TextStats,
+		lines:         res.Lines,
+		bytes:         res.Bytes,
+		chars:         res.Chars,
+		size:          total,
+		executionTime: float64(duration.Microseconds()) / 1000.0,
+		memory:        memoryDelta(startMem, endMem),
+		notes:         notes,
+		variants:      variants,
+	}, stopped
+}
+
+// presentTotal reports a -count-only run: the total alone on stdout, as
+// wc -w prints it, and the sizes and timing on the console. Errors are
+// returned as by present.
+func presentTotal(cfg config, a *analysis) error {
+	con := cfg.console()
+	fmt.Fprintln(con, "\n=== Statistics ===")
+	if len(a.filenames) > 1 {
+		fmt.Fprintf(con, "Files processed: %d\n", len(a.filenames))
+	}
+	fmt.Fprintf(con, "File size:       %.2f MB\n", float64(a.size.bytes)/(1024.0*1024.0))
+	fmt.Fprintf(con, "Total words:     %s\n", formatNumber(a.totalWords))
+	if a.textStats {
+		fmt.Fprintf(con, "Lines:           %s\n", formatNumber(a.lines))
+		fmt.Fprintf(con, "Characters:      %s\n", formatNumber(a.chars))
+		fmt.Fprintf(con, "Bytes:           %s\n", formatNumber(a.bytes))
+	}
+	fmt.Fprintf(con, "Execution time:  %.2f ms\n", a.executionTime)
+	if len(a.runTimes) > 1 {
+		lo, median, mean := timingSummary(a.runTimes)
+		fmt.Fprintf(con, "Runs:            %d (min %.2f ms, median %.2f ms, mean %.2f ms)\n",
+			len(a.runTimes), lo, median, mean)
+	}
+	if cfg.baseline != nil {
+		writeBaseline(con, cfg, a)
+	}
+	for _, note := range a.notes[len(cfg.notes):] {
+		fmt.Fprintf(con, "\nNote: %s\n", note)
+	}
+	fmt.Println(a.totalWords)
+	if cfg.metrics != "" {
+		if err := writeMetrics(cfg.metrics, cfg, a); err != nil {
+			return fmt.Errorf("writing metrics file: %w", err)
+		}
+	}
+	if err := checkBaseline(cfg, a); err != nil {
+		return err
+	}
+	if cfg.failEmpty && a.totalWords == 0 {
+		return fmt.Errorf("%w in %s", errNoWords, (&report{filenames: a.filenames}).inputNames())
+	}
+	return nil
+}
+
+// timingSummary returns the minimum, median and mean of the run times.
+func timingSummary(times []float64) (lo, median, mean float64) {
+	sorted := slices.Clone(times)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		median = sorted[n/2]
+	} else {
+		median = (sorted[n/2-1] + sorted[n/2]) / 2
+	}
+	var sum float64
+	for _, t := range sorted {
+		sum += t
+	}
+	return sorted[0], median, sum / float64(n)
+}
+
+// stopReason describes why counting ended early: ctx was done, or
+// reading the input failed.
+func stopReason(err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "-timeout reached"
+	case errors.Is(err, context.Canceled):
+		return "interrupted"
+	}
+	return err.Error()
+}
+
+// run counts filenames, prints the console summary and writes the results
+// file.
+// If counting is stopped early by ctx, the partial results are still
+// reported before the error is returned.
+func run(ctx context.Context, cfg config, filenames []string) error {
+	con := cfg.console()
+	for _, filename := range filenames {
+		fmt.Fprintf(con, "Processing file: %s\n", displayName(filename))
+	}
+
+	// Each run starts from scratch, so only the last one's counts are kept.
+	var a *analysis
+	var err error
+	var times []float64
+	for i := 0; i < cfg.repeat; i++ {
+		a, err = analyze(ctx, cfg, filenames)
+		if a == nil {
+			return err
+		}
+		times = append(times, a.executionTime)
+		if err != nil {
+			break
+		}
+	}
+	a.runTimes = times
+	if perr := present(cfg, a); perr != nil {
+		return perr
+	}
+	if cfg.repl {
+		if rerr := repl(os.Stdin, os.Stdout, con, a, cfg.opts); rerr != nil {
+			return rerr
+		}
+	}
+	return err
+}
+
+// present prints the console summary of a and writes its results file.
+// A failed write does not stop the others; the failures are returned
+// together. errNoWords is returned under -fail-empty only when every
+// write succeeded, so a failed write always exits with status 1.
+func present(cfg config, a *analysis) error {
+	con := cfg.console()
+	filenames := a.filenames
+	sorted := a.sorted
+
+	if cfg.countOnly {
+		return presentTotal(cfg, a)
+	}
+
+	fmt.Fprintf(con, "\n=== %s ===\n", listTitle(cfg.order, cfg.consoleTop))
+	limit := topLimit(cfg.consoleTop, len(sorted))
+	for i := 0; i < limit; i++ {
+		fmt.Fprintf(con, "%2d. %-15s %9s\n", i+1, sorted[i].Word, formatNumber(int64(sorted[i].Count)))
+	}
+
+	if cfg.bottom > 0 {
+		fmt.Fprintf(con, "\n=== Bottom %d Least Frequent Words ===\n", cfg.bottom)
+		for i, wc := range a.bottom {
+			fmt.Fprintf(con, "%2d. %-15s %9s\n", i+1, wc.Word, formatNumber(int64(wc.Count)))
+		}
+	}
+
+	if a.lengths != nil {
+		fmt.Fprintln(con, "\n=== Word Length Distribution ===")
+		writeLengths(con, a.lengths, cfg.precision)
+	}
+
+	byCount := sorted
+	if cfg.order != sortCount && (cfg.coverage != nil || cfg.perLength > 0 || cfg.dict != nil) {
+		byCount = slices.Clone(sorted)
+		less := cfg.orderOf(sortCount)
+		sort.Slice(byCount, func(i, j int) bool { return less(byCount[i], byCount[j]) })
+	}
+
+	var groups []lengthGroup
+	if cfg.perLength > 0 {
+		groups = topPerLength(byCount, cfg.perLength, cfg.opts.Unicode)
+		fmt.Fprintf(con, "\n=== Top %d Words per Length ===\n", cfg.perLength)
+		writePerLength(con, groups)
+	}
+
+	if len(a.variants) > 0 {
+		fmt.Fprintln(con, "\n=== Grouped Variants ===")
+		writeVariants(con, sorted[:limit], a.variants)
+	}
+
+	var unknown []wordfreq.WordCount
+	if cfg.dict != nil {
+		unknown = notInDictionary(byCount, cfg.dict, cfg.opts)
+		fmt.Fprintf(con, "\n=== Words Not in Dictionary ===\n")
+		for i, wc := range unknown[:topLimit(cfg.consoleTop, len(unknown))] {
+			fmt.Fprintf(con, "%2d. %-15s %9s\n", i+1, wc.Word, formatNumber(int64(wc.Count)))
+		}
+		fmt.Fprintln(con, unknownSummary(unknown, a.uniqueWords, a.totalWords))
+	}
+
+	var hist []freqBucket
+	if cfg.freqHist {
+		hist = freqHistogram(sorted)
+		fmt.Fprintln(con, "\n=== Frequency Distribution ===")
+		writeFreqHistogram(con, hist, a.uniqueWords)
+	}
+
+	var points []coveragePoint
+	if cfg.coverage != nil {
+		points = coverage(byCount, a.totalWords, cfg.coverage)
+		fmt.Fprintln(con, "\n=== Vocabulary Coverage ===")
+		writeCoverage(con, points, a.uniqueWords)
+	}
+
+	fileSize := float64(a.size.bytes) / (1024.0 * 1024.0)
+	fmt.Fprintln(con, "\n=== Statistics ===")
+	if len(filenames) > 1 {
+		fmt.Fprintf(con, "Files processed: %d\n", len(filenames))
+	}
+	if a.size.compressed {
+		fmt.Fprintf(con, "File size:       %.2f MB (compressed, %.2f MB uncompressed)\n",
+			fileSize, float64(a.size.decoded)/(1024.0*1024.0))
+	} else {
+		fmt.Fprintf(con, "File size:       %.2f MB\n", fileSize)
+	}
+	fmt.Fprintf(con, "Total words:     %s\n", formatNumber(a.totalWords))
+	fmt.Fprintf(con, "Unique words:    %s\n", formatNumber(int64(a.uniqueWords)))
+	if a.textStats {
+		fmt.Fprintf(con, "Lines:           %s\n", formatNumber(a.lines))
+		fmt.Fprintf(con, "Characters:      %s\n", formatNumber(a.chars))
+		fmt.Fprintf(con, "Bytes:           %s\n", formatNumber(a.bytes))
+	}
+	fmt.Fprintf(con, "Execution time:  %.2f ms\n", a.executionTime)
+	if len(a.runTimes) > 1 {
+		lo, median, mean := timingSummary(a.runTimes)
+		fmt.Fprintf(con, "Runs:            %d (min %.2f ms, median %.2f ms, mean %.2f ms)\n",
+			len(a.runTimes), lo, median, mean)
+	}
+	fmt.Fprintf(con, "Allocated:       %.2f MB\n", float64(a.memory.allocated)/(1024.0*1024.0))
+	fmt.Fprintf(con, "Allocations:     %s\n", formatNumber(int64(a.memory.mallocs)))
+	fmt.Fprintf(con, "GC cycles:       %d\n", a.memory.gcs)
+	fmt.Fprintf(con, "Heap growth:     %.2f MB\n", float64(a.memory.heapGrowth)/(1024.0*1024.0))
+	fmt.Fprintf(con, "Go version:      %s\n", runtime.Version())
+	fmt.Fprintf(con, "CPU cores:       %d\n", runtime.NumCPU())
+	fmt.Fprintf(con, "GOMAXPROCS:      %d\n", runtime.GOMAXPROCS(0))
+	fmt.Fprintf(con, "Workers:         %d\n", cfg.workers)
+	if cfg.baseline != nil {
+		writeBaseline(con, cfg, a)
+	}
+
+	// Notes from the command line are already in the results header; only
+	// the ones discovered while counting are worth repeating here.
+	for _, note := range a.notes[len(cfg.notes):] {
+		fmt.Fprintf(con, "\nNote: %s\n", note)
+	}
+
+	rep := report{
+		filenames:     filenames,
+		sorted:        sorted,
+		order:         cfg.order,
+		bottom:        a.bottom,
+		totalWords:    a.totalWords,
+		uniqueWords:   a.uniqueWords,
+		executionTime: a.executionTime,
+		top:           cfg.fileTop,
+		precision:     cfg.precision,
+		stable:        cfg.stable,
+		compact:       cfg.compact,
+		notes:         a.notes,
+		lengths:       a.lengths,
+		coverage:      points,
+		perLength:     groups,
+		variants:      a.variants,
+		freqHist:      hist,
+		unknown:       unknown,
+		checked:       cfg.dict != nil,
+		charset:       cfg.outCharset,
+	}
+	var errs []error
+	if cfg.metrics != "" {
+		if err := writeMetrics(cfg.metrics, cfg, a); err != nil {
+			errs = append(errs, fmt.Errorf("writing metrics file: %w", err))
+		}
+	}
+	if !cfg.noFile {
+		if err := writeOutputFile(con, cfg.format, cfg.output, cfg.compress, rep); err != nil {
+			errs = append(errs, fmt.Errorf("writing output file: %w", err))
+		}
+	}
+	if cfg.bands != nil {
+		if err := writeBands(con, cfg, rep); err != nil {
+			errs = append(errs, fmt.Errorf("writing band files: %w", err))
+		}
+	}
+	if err := checkBaseline(cfg, a); err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if cfg.failEmpty && a.totalWords == 0 {
+		return fmt.Errorf("%w in %s", errNoWords, rep.inputNames())
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSample writes about size bytes of pseudo-random words to a file in
+// a test's temporary directory and returns its name.
+func writeSample(tb testing.TB, size int) string {
+	tb.Helper()
+	rng := rand.New(rand.NewSource(1))
+	words := strings.Fields("the of and to a in that it is was he for on are as with his they at be this from")
+	var text strings.Builder
+	text.Grow(size + 16)
+	for text.Len() < size {
+		text.WriteString(words[rng.Intn(len(words))])
+		if rng.Intn(12) == 0 {
+			text.WriteString(".\n")
+		} else {
+			text.WriteByte(' ')
+		}
+	}
+	return writeFile(tb, "sample.txt", []byte(text.String()))
+}
+
+func BenchmarkProcessFile(b *testing.B) {
+	const size = 4 * 1024 * 1024
+	name := writeSample(b, size)
+	for _, bm := range []struct {
+		name string
+		cfg  config
+	}{
+		{"read", config{workers: 1}},
+		{"mmap", config{workers: 1, mmap: true}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			b.SetBytes(size)
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, _, err := countFile(context.Background(), bm.cfg, name); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{7, "7"},
+		{999, "999"},
+		{1000, "1,000"},
+		{123456, "123,456"},
+		{1234567, "1,234,567"},
+		{-1, "-1"},
+		{-999, "-999"},
+		{-123456, "-123,456"},
+		{-1234567, "-1,234,567"},
+		{math.MaxInt64, "9,223,372,036,854,775,807"},
+		{math.MinInt64, "-9,223,372,036,854,775,808"},
+	}
+	for _, tt := range tests {
+		if got := formatNumber(tt.n); got != tt.want {
+			t.Errorf("formatNumber(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryDelta(t *testing.T) {
+	// A collection during the run can leave less on the heap than before.
+	start := runtime.MemStats{TotalAlloc: 100, Mallocs: 10, NumGC: 1, HeapAlloc: 5000}
+	end := runtime.MemStats{TotalAlloc: 900, Mallocs: 30, NumGC: 3, HeapAlloc: 2000}
+	got := memoryDelta(&start, &end)
+	want := memoryStats{allocated: 800, mallocs: 20, gcs: 2, heapGrowth: 0}
+	if got != want {
+		t.Errorf("memoryDelta = %+v, want %+v", got, want)
+	}
+	if got := memoryDelta(&end, &start); got.allocated != 0 || got.mallocs != 0 || got.gcs != 0 {
+		t.Errorf("memoryDelta of decreasing counters = %+v, want zeros", got)
+	}
+}
+
+func TestListLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config
+		want int
+	}{
+		{"defaults", config{consoleTop: 10, fileTop: 100}, 100},
+		{"console longer", config{consoleTop: 500, fileTop: 100}, 500},
+		{"full", config{consoleTop: 10, fileTop: 0}, 0},
+		{"full no-output-file", config{consoleTop: 10, fileTop: 0, noFile: true}, 10},
+		{"console all", config{consoleTop: 0, fileTop: 100}, 0},
+		{"console all quiet", config{consoleTop: 0, fileTop: 100, quiet: true}, 100},
+		{"full quiet", config{consoleTop: 10, fileTop: 0, quiet: true}, 0},
+		{"no-output-file", config{consoleTop: 10, fileTop: 100, noFile: true}, 10},
+		{"bottom", config{consoleTop: 10, fileTop: 100, bottom: 5}, 0},
+		{"keep all", config{consoleTop: 10, fileTop: 100, keepAll: true}, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.cfg.listLimit(); got != tt.want {
+			t.Errorf("%s: listLimit() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPresentWriteErrors(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing")
+	cfg := config{
+		quiet:     true,
+		format:    formatText,
+		order:     sortCount,
+		output:    filepath.Join(missing, "results.txt"),
+		metrics:   filepath.Join(missing, "metrics.json"),
+		failEmpty: true,
+	}
+	err := present(cfg, &analysis{filenames: []string{"empty.txt"}})
+	if err == nil {
+		t.Fatal("present returned nil with both files unwritable")
+	}
+	for _, file := range []string{"metrics file", "output file"} {
+		if !strings.Contains(err.Error(), "writing "+file) {
+			t.Errorf("error %q does not report the %s", err, file)
+		}
+	}
+	if errors.Is(err, errNoWords) {
+		t.Errorf("error %q reports no words as well as the failed writes", err)
+	}
+
+	cfg.output, cfg.metrics = filepath.Join(t.TempDir(), "results.txt"), ""
+	if err := present(cfg, &analysis{filenames: []string{"empty.txt"}}); !errors.Is(err, errNoWords) {
+		t.Errorf("present = %v, want %v", err, errNoWords)
+	}
+}
+
+func TestNoSingle(t *testing.T) {
+	filename := writeFile(t, "single.txt", []byte("a I é ab én xyz I a\n"))
+	tests := []struct {
+		unicode bool
+		minLen  int
+		want    map[string]int
+	}{
+		{false, 0, map[string]int{"ab": 1, "xyz": 1}},
+		{false, 1, map[string]int{"ab": 1, "xyz": 1}},
+		{false, 3, map[string]int{"xyz": 1}},
+		{true, 0, map[string]int{"ab": 1, "én": 1, "xyz": 1}},
+		{true, 3, map[string]int{"xyz": 1}},
+	}
+	for _, tt := range tests {
+		for _, workers := range []int{1, 4} {
+			cfg := config{workers: workers}
+			cfg.opts.Unicode = tt.unicode
+			cfg.opts.MinLength = effectiveMinLen(tt.minLen, true)
+			res, _, err := countFile(context.Background(), cfg, filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(res.Counts, tt.want) {
+				t.Errorf("-no-single -min-len %d, unicode %v, %d workers: got %v, want %v",
+					tt.minLen, tt.unicode, workers, res.Counts, tt.want)
+			}
+		}
+	}
+	if got := effectiveMinLen(3, false); got != 3 {
+		t.Errorf("effectiveMinLen(3, false) = %d, want 3", got)
+	}
+}
+
+func TestRunContextTimeout(t *testing.T) {
+	// Run with -race, this also covers the signal goroutine, which once
+	// raced with the timeout context replacing the one it waited on.
+	ctx, cancel := runContext(10 * time.Millisecond)
+	defer cancel()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context not done after its timeout")
+	}
+	if !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Fatalf("ctx.Err() = %v, want %v", ctx.Err(), context.DeadlineExceeded)
+	}
+
+	cfg := config{workers: 1, quiet: true, order: sortCount, log: newLogger(false, false, true)}
+	a, err := analyze(ctx, cfg, []string{writeSample(t, 1024*1024)})
+	if a == nil {
+		t.Fatalf("analyze returned no results: %v", err)
+	}
+	if err == nil || !strings.Contains(err.Error(), "-timeout reached") {
+		t.Errorf("analyze error = %v, want the -timeout reached", err)
+	}
+
+	untimed, cancel := runContext(0)
+	defer cancel()
+	if untimed.Err() != nil {
+		t.Errorf("context without a timeout is done: %v", untimed.Err())
+	}
+}
+
+func TestAffixes(t *testing.T) {
+	filename := writeFile(t, "affix.txt", []byte("Rewrites rewrite reads Redo read writes REDS\n"))
+	for _, tt := range []struct {
+		prefix, suffix string
+		want           map[string]int
+	}{
+		{"Re", "", map[string]int{"rewrites": 1, "rewrite": 1, "reads": 1, "redo": 1, "read": 1, "reds": 1}},
+		{"", "S", map[string]int{"rewrites": 1, "reads": 1, "writes": 1, "reds": 1}},
+		{"re", "s", map[string]int{"rewrites": 1, "reads": 1, "reds": 1}},
+		{"x", "", map[string]int{}},
+	} {
+		cfg := testConfig()
+		cfg.prefix, cfg.suffix = foldCase(cfg.opts, tt.prefix), foldCase(cfg.opts, tt.suffix)
+		a, err := analyze(context.Background(), cfg, []string{filename})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := countMap(a.sorted); !maps.Equal(got, tt.want) {
+			t.Errorf("-prefix %q -suffix %q: got %v, want %v", tt.prefix, tt.suffix, got, tt.want)
+		}
+		// Words left out still count towards the total.
+		if a.totalWords != 7 {
+			t.Errorf("-prefix %q -suffix %q: total %d, want 7", tt.prefix, tt.suffix, a.totalWords)
+		}
+	}
+}
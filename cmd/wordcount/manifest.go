@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readManifest returns the paths listed one per line in the -files-from
+// file. Blank lines and lines starting with # are skipped, and paths are
+// taken relative to the working directory like command-line arguments.
+func readManifest(filename string) ([]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var paths []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		paths = append(paths, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// errDirectory is returned by expandDirs for a directory argument without
+// -recursive.
+var errDirectory = errors.New("expected a file, got a directory (use -recursive to count the .txt files in it)")
+
+// expandDirs replaces each directory in filenames by the .txt files (and
+// gzipped .txt.gz files) below it, in lexical order, when recursive is
+// set; otherwise a directory is an error. Other paths are kept as given.
+func expandDirs(filenames []string, recursive bool) ([]string, error) {
+	var out []string
+	for _, filename := range filenames {
+		info, err := os.Stat(filename)
+		if filename == stdinName || err != nil || !info.IsDir() {
+			out = append(out, filename)
+			continue
+		}
+		if !recursive {
+			return nil, fmt.Errorf("%s: %w", filename, errDirectory)
+		}
+		found := 0
+		err = filepath.WalkDir(filename, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.Type().IsRegular() && (strings.HasSuffix(path, ".txt") || strings.HasSuffix(path, ".txt.gz")) {
+				out = append(out, path)
+				found++
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("no .txt files found in %s", filename)
+		}
+	}
+	return out, nil
+}
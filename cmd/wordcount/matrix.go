@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// matrixName is the default -matrix output file.
+const matrixName = "matrix_go_results.csv"
+
+// termMatrix holds the counts of every word in each of several documents.
+type termMatrix struct {
+	docs   []string             // document names, one per column
+	words  []wordfreq.WordCount // rows, by descending total count
+	counts []map[string]int     // counts[i] is the counts of docs[i]
+	totals []int64              // totals[i] is the number of words in docs[i]
+}
+
+// countDocuments counts each of filenames on its own, one document per
+// file. Words whose total count over all documents is below minCount are
+// left out of words but kept in counts.
+func countDocuments(ctx context.Context, cfg config, filenames []string, minCount int) (*termMatrix, error) {
+	cfg.keepAll = true
+	cfg.minCount = 0
+	m := &termMatrix{}
+	totals := make(map[string]int)
+	for _, filename := range filenames {
+		a, err := analyze(ctx, cfg, []string{filename})
+		if err != nil {
+			return nil, err
+		}
+		counts := countMap(a.sorted)
+		for word, n := range counts {
+			totals[word] += n
+		}
+		m.docs = append(m.docs, displayName(filename))
+		m.counts = append(m.counts, counts)
+		m.totals = append(m.totals, a.totalWords)
+	}
+	for word, n := range totals {
+		if n < minCount {
+			delete(totals, word)
+		}
+	}
+	m.words = wordfreq.Sort(totals)
+	return m, nil
+}
+
+// runMatrix counts each of filenames separately and writes a CSV
+// term-document matrix: a header row of file names, then one row per
+// word with its count in each file.
+func runMatrix(ctx context.Context, cfg config, filenames []string) error {
+	m, err := countDocuments(ctx, cfg, filenames, cfg.minCount)
+	if err != nil {
+		return err
+	}
+	if len(m.words) == 0 {
+		return fmt.Errorf("%w in %s", errNoWords, (&report{filenames: filenames}).inputNames())
+	}
+
+	name := cfg.output
+	if name == "" {
+		name = matrixName
+	}
+	out := os.Stdout
+	if name != stdinName {
+		out, err = os.Create(name)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	w := bufio.NewWriterSize(out, 32*1024)
+	if err := writeMatrix(w, m); err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if name != stdinName {
+		fmt.Fprintf(cfg.console(), "\nMatrix of %s words in %d files written to: %s\n",
+			formatNumber(int64(len(m.words))), len(m.docs), name)
+	}
+	return nil
+}
+
+// writeMatrix writes m as CSV, quoting words and file names as needed.
+func writeMatrix(w io.Writer, m *termMatrix) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(append([]string{"word"}, m.docs...)); err != nil {
+		return err
+	}
+	record := make([]string, len(m.docs)+1)
+	for _, wc := range m.words {
+		record[0] = wc.Word
+		for i, counts := range m.counts {
+			record[i+1] = strconv.Itoa(counts[wc.Word])
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRunMatrix(t *testing.T) {
+	a := writeFile(t, "a.txt", []byte("the cat sat on the mat"))
+	b := writeFile(t, "b,1.txt", []byte("the dog sat"))
+	cfg := testConfig()
+	cfg.minCount = 2
+	cfg.output = filepath.Join(t.TempDir(), "matrix.csv")
+	if err := runMatrix(context.Background(), cfg, []string{a, b}); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(cfg.output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// -min-count applies to the total over all files: "sat" is kept with
+	// one in each, while "cat", "dog", "on" and "mat" are left out.
+	want := [][]string{{"word", a, b}, {"the", "2", "1"}, {"sat", "1", "1"}}
+	if !slices.EqualFunc(rows, want, slices.Equal[[]string]) {
+		t.Errorf("matrix = %q, want %q", rows, want)
+	}
+
+	cfg.minCount = 10
+	if err := runMatrix(context.Background(), cfg, []string{a, b}); !errors.Is(err, errNoWords) {
+		t.Errorf("matrix with no word above -min-count: error %v, want errNoWords", err)
+	}
+}
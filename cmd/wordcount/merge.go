@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// savedResults is the word list recovered from one results file.
+type savedResults struct {
+	counts     map[string]int
+	totalWords int64 // from the header, or the sum of counts if absent
+	complete   bool  // the file is known to list every unique word
+	format     string
+}
+
+// runMerge sums the counts saved in previously written results files and
+// reports them as if they had been counted together.
+func runMerge(cfg config, filenames []string) error {
+	con := cfg.console()
+	runtime.GC()
+	startTime := time.Now()
+	var startMem, endMem runtime.MemStats
+	runtime.ReadMemStats(&startMem)
+
+	merged := make(map[string]int)
+	var totalWords int64
+	var size inputSize
+	complete := true
+	for _, filename := range filenames {
+		fmt.Fprintf(con, "Merging results file: %s\n", displayName(filename))
+		saved, n, err := readResultsFile(filename)
+		if err != nil {
+			return fmt.Errorf("%s: %w", displayName(filename), err)
+		}
+		for word, count := range saved.counts {
+			merged[word] += count
+		}
+		totalWords += saved.totalWords
+		size.bytes += n
+		complete = complete && saved.complete
+	}
+
+	notes := append(cfg.notes, fmt.Sprintf("Merged from %d results files", len(filenames)))
+	if !complete {
+		notes = append(notes, "Some results files list only their top words (see -top), so merged counts "+
+			"omit words a file left out and unique words is a lower bound")
+	}
+
+	sorted, rarest := sortCounts(cfg, merged, nil)
+	duration := time.Since(startTime)
+	runtime.ReadMemStats(&endMem)
+	return present(cfg, &analysis{
+		filenames:     filenames,
+		sorted:        sorted,
+		bottom:        rarest,
+		totalWords:    totalWords,
+		uniqueWords:   len(merged),
+		size:          size,
+		executionTime: float64(duration.Microseconds()) / 1000.0,
+		memory:        memoryDelta(&startMem, &endMem),
+		notes:         notes,
+	})
+}
+
+// readResultsFile parses a results file in any of the -format layouts,
+// recognized by its first line, and returns it with its size in bytes.
+func readResultsFile(filename string) (*savedResults, int64, error) {
+	var data []byte
+	var err error
+	if filename == stdinName {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filename)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	first, _, _ := bytes.Cut(data, []byte("\n"))
+	var saved *savedResults
+	var format string
+	switch {
+	case bytes.HasPrefix(first, []byte("Word Frequency Analysis")):
+		saved, err = parseTextResults(data)
+		format = formatText
+	case bytes.HasPrefix(first, []byte("rank,word,count")):
+		saved, err = parseCSVResults(data)
+		format = formatCSV
+	case bytes.HasPrefix(first, []byte("word\tcount")):
+		saved, err = parseTSVResults(data)
+		format = formatTSV
+	case bytes.Equal(bytes.TrimSpace(first), []byte("{")):
+		saved, err = parseJSONResults(data)
+		format = formatJSON
+	case bytes.HasPrefix(first, []byte("{")):
+		saved, err = parseJSONLResults(data)
+		format = formatJSONL
+	default:
+		return nil, 0, fmt.Errorf("not a results file")
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	saved.format = format
+	return saved, int64(len(data)), nil
+}
+
+// parseTextResults reads the header totals and the first word table of a
+// text results file; any bottom or length tables that follow are ignored.
+func parseTextResults(data []byte) (*savedResults, error) {
+	saved := &savedResults{counts: make(map[string]int)}
+	unique := -1
+	inTable, compact := false, false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case inTable && strings.TrimSpace(line) == "":
+			saved.complete = len(saved.counts) == unique
+			return saved, nil
+		case inTable:
+			if strings.HasPrefix(line, "----") {
+				continue
+			}
+			fields := strings.Fields(line)
+			if compact {
+				if len(fields) < 2 {
+					return nil, fmt.Errorf("malformed word line %q", line)
+				}
+				count, err := strconv.Atoi(fields[len(fields)-1])
+				if err != nil {
+					return nil, fmt.Errorf("malformed count in line %q", line)
+				}
+				saved.counts[strings.Join(fields[:len(fields)-1], " ")] += count
+				continue
+			}
+			if len(fields) < 4 {
+				return nil, fmt.Errorf("malformed table row %q", line)
+			}
+			count, err := strconv.Atoi(strings.ReplaceAll(fields[len(fields)-2], ",", ""))
+			if err != nil {
+				return nil, fmt.Errorf("malformed count in row %q", line)
+			}
+			saved.counts[strings.Join(fields[1:len(fields)-2], " ")] += count
+		case strings.HasPrefix(line, "Total words: "):
+			n, err := strconv.ParseInt(strings.ReplaceAll(strings.TrimPrefix(line, "Total words: "), ",", ""), 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("malformed %q", line)
+			}
+			saved.totalWords = n
+		case strings.HasPrefix(line, "Unique words: "):
+			n, err := strconv.Atoi(strings.ReplaceAll(strings.TrimPrefix(line, "Unique words: "), ",", ""))
+			if err != nil {
+				return nil, fmt.Errorf("malformed %q", line)
+			}
+			unique = n
+		case strings.HasPrefix(line, "Rank  Word"):
+			inTable = true
+		case line == compactHeader:
+			inTable, compact = true, true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	saved.complete = len(saved.counts) == unique
+	return saved, nil
+}
+
+func parseJSONResults(data []byte) (*savedResults, error) {
+	var doc jsonReport
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	saved := &savedResults{
+		counts:     make(map[string]int, len(doc.Words)),
+		totalWords: doc.TotalWords,
+		complete:   len(doc.Words) == doc.UniqueWords,
+	}
+	for _, e := range doc.Words {
+		saved.counts[e.Word] += e.Count
+	}
+	return saved, nil
+}
+
+// parseJSONLResults, parseCSVResults and parseTSVResults read formats
+// without totals, so the total is the sum of the listed counts and the list
+// may be partial.
+func parseJSONLResults(data []byte) (*savedResults, error) {
+	saved := &savedResults{counts: make(map[string]int)}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var e jsonlEntry
+		if err := dec.Decode(&e); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		saved.counts[e.Word] += e.Count
+		saved.totalWords += int64(e.Count)
+	}
+	return saved, nil
+}
+
+func parseCSVResults(data []byte) (*savedResults, error) {
+	saved := &savedResults{counts: make(map[string]int)}
+	cr := csv.NewReader(bytes.NewReader(data))
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records[1:] {
+		count, err := strconv.Atoi(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("malformed count in row %q", strings.Join(record, ","))
+		}
+		saved.counts[record[1]] += count
+		saved.totalWords += int64(count)
+	}
+	return saved, nil
+}
+
+func parseTSVResults(data []byte) (*savedResults, error) {
+	saved := &savedResults{counts: make(map[string]int)}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1024*1024)
+	scanner.Scan() // header
+	for scanner.Scan() {
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("malformed row %q", scanner.Text())
+		}
+		count, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("malformed count in row %q", scanner.Text())
+		}
+		saved.counts[fields[0]] += count
+		saved.totalWords += int64(count)
+	}
+	return saved, scanner.Err()
+}
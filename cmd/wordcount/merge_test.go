@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// BenchmarkMerge compares -merge of saved results files with counting the
+// inputs they were saved from again.
+func BenchmarkMerge(b *testing.B) {
+	const files, size = 4, 1024 * 1024
+	dir := b.TempDir()
+	cfg := config{
+		workers: 1,
+		quiet:   true,
+		format:  formatTSV,
+		order:   sortCount,
+		output:  filepath.Join(dir, "merged.tsv"),
+		log:     newLogger(false, false, true),
+	}
+	var inputs, saved []string
+	for i := 0; i < files; i++ {
+		input := writeSample(b, size)
+		res, _, err := countFile(context.Background(), cfg, input)
+		if err != nil {
+			b.Fatal(err)
+		}
+		name := filepath.Join(dir, fmt.Sprintf("results%d.tsv", i))
+		out, err := os.Create(name)
+		if err != nil {
+			b.Fatal(err)
+		}
+		rep := &report{sorted: wordfreq.Sort(res.Counts), totalWords: res.TotalWords, uniqueWords: len(res.Counts)}
+		if err := writeTSV(out, rep); err != nil {
+			b.Fatal(err)
+		}
+		if err := out.Close(); err != nil {
+			b.Fatal(err)
+		}
+		inputs = append(inputs, input)
+		saved = append(saved, name)
+	}
+
+	b.Run("merge", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if err := runMerge(cfg, saved); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("recount", func(b *testing.B) {
+		b.SetBytes(files * size)
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			a, err := analyze(context.Background(), cfg, inputs)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := present(cfg, a); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestResultsRoundTrip writes each results format and reads it back as
+// -merge and -append do. Only text and JSON record the totals, so the
+// other formats give the sum of the listed counts and never claim to list
+// every word.
+func TestResultsRoundTrip(t *testing.T) {
+	counts := map[string]int{"the": 5, "don't": 3, "well-being": 3, "café": 2, "new york": 1, "a,b": 1, `"quoted"`: 1}
+	for _, tt := range []struct {
+		format  string
+		compact bool
+		header  bool
+	}{
+		{formatText, false, true},
+		{formatText, true, true},
+		{formatJSON, false, true},
+		{formatJSONL, false, false},
+		{formatCSV, false, false},
+		{formatTSV, false, false},
+	} {
+		for _, top := range []int{0, 2} {
+			name := fmt.Sprintf("%s compact=%v top=%d", tt.format, tt.compact, top)
+			filename := filepath.Join(t.TempDir(), "results."+tt.format)
+			sorted := wordfreq.Sort(counts)
+			rep := report{filenames: []string{"a.txt"}, sorted: sorted, order: sortCount, totalWords: 20,
+				uniqueWords: len(counts), top: top, precision: 2, compact: tt.compact}
+			if err := writeOutputFile(io.Discard, tt.format, filename, false, rep); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			saved, _, err := readResultsFile(filename)
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+
+			want := make(map[string]int)
+			var listed int64
+			for _, wc := range sorted[:topLimit(top, len(sorted))] {
+				want[wc.Word] = wc.Count
+				listed += int64(wc.Count)
+			}
+			wantTotal, wantComplete := listed, false
+			if tt.header {
+				wantTotal, wantComplete = 20, top == 0
+			}
+			if !maps.Equal(saved.counts, want) {
+				t.Errorf("%s: counts = %v, want %v", name, saved.counts, want)
+			}
+			if saved.totalWords != wantTotal || saved.complete != wantComplete || saved.format != tt.format {
+				t.Errorf("%s: total %d, complete %v, format %s; want %d, %v, %s", name,
+					saved.totalWords, saved.complete, saved.format, wantTotal, wantComplete, tt.format)
+			}
+		}
+	}
+}
+
+func TestRunMerge(t *testing.T) {
+	dir := t.TempDir()
+	var saved []string
+	for i, tt := range []struct {
+		format string
+		counts map[string]int
+		top    int
+	}{
+		{formatText, map[string]int{"the": 4, "cat": 2, "sat": 1}, 0},
+		{formatCSV, map[string]int{"the": 3, "dog": 2}, 0},
+		{formatJSON, map[string]int{"the": 5, "cat": 1, "mat": 1}, 2},
+	} {
+		sorted := wordfreq.Sort(tt.counts)
+		var total int64
+		for _, wc := range sorted {
+			total += int64(wc.Count)
+		}
+		rep := report{filenames: []string{"a.txt"}, sorted: sorted, order: sortCount, totalWords: total,
+			uniqueWords: len(sorted), top: tt.top, precision: 2}
+		name := filepath.Join(dir, fmt.Sprintf("results%d.%s", i, tt.format))
+		if err := writeOutputFile(io.Discard, tt.format, name, false, rep); err != nil {
+			t.Fatal(err)
+		}
+		saved = append(saved, name)
+	}
+
+	cfg := testConfig()
+	cfg.format, cfg.fileTop = formatJSON, 0
+	cfg.output = filepath.Join(dir, "merged.json")
+	if err := runMerge(cfg, saved); err != nil {
+		t.Fatal(err)
+	}
+	merged, _, err := readResultsFile(cfg.output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The JSON file lists only its top two words, leaving out "mat", though
+	// its header still adds all seven to the total.
+	want := map[string]int{"the": 12, "cat": 3, "dog": 2, "sat": 1}
+	if !maps.Equal(merged.counts, want) {
+		t.Errorf("merged counts = %v, want %v", merged.counts, want)
+	}
+	if merged.totalWords != 19 {
+		t.Errorf("merged total = %d, want 19", merged.totalWords)
+	}
+}
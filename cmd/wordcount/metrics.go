@@ -0,0 +1,159 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"runtime"
+)
+
+// jsonMetrics is the document written by -metrics: the Statistics block
+// as structured data for benchmark harnesses.
+type jsonMetrics struct {
+	Implementation  string    `json:"implementation"`
+	Files           []string  `json:"files"`
+	FileSizeBytes   int64     `json:"file_size_bytes"`
+	DecodedBytes    int64     `json:"decoded_bytes,omitempty"`
+	TotalWords      int64     `json:"total_words"`
+	UniqueWords     int       `json:"unique_words"`
+	ExecutionTimeMS float64   `json:"execution_time_ms"`
+	RunTimesMS      []float64 `json:"run_times_ms,omitempty"`
+	AllocatedBytes  uint64    `json:"allocated_bytes"`
+	Allocations     uint64    `json:"allocations"`
+	GCCycles        uint32    `json:"gc_cycles"`
+	HeapGrowthBytes uint64    `json:"heap_growth_bytes"`
+	GoVersion       string    `json:"go_version"`
+	CPUCores        int       `json:"cpu_cores"`
+	GOMAXPROCS      int       `json:"gomaxprocs"`
+	Workers         int       `json:"workers"`
+}
+
+// writeMetrics writes the measurements of a to filename, or to stdout
+// when filename is "-".
+func writeMetrics(filename string, cfg config, a *analysis) error {
+	doc := jsonMetrics{
+		Implementation:  "go",
+		Files:           make([]string, len(a.filenames)),
+		FileSizeBytes:   a.size.bytes,
+		TotalWords:      a.totalWords,
+		UniqueWords:     a.uniqueWords,
+		ExecutionTimeMS: a.executionTime,
+		AllocatedBytes:  a.memory.allocated,
+		Allocations:     a.memory.mallocs,
+		GCCycles:        a.memory.gcs,
+		HeapGrowthBytes: a.memory.heapGrowth,
+		GoVersion:       runtime.Version(),
+		CPUCores:        runtime.NumCPU(),
+		GOMAXPROCS:      runtime.GOMAXPROCS(0),
+		Workers:         cfg.workers,
+	}
+	for i, filename := range a.filenames {
+		doc.Files[i] = displayName(filename)
+	}
+	if a.size.compressed {
+		doc.DecodedBytes = a.size.decoded
+	}
+	if len(a.runTimes) > 1 {
+		doc.RunTimesMS = a.runTimes
+	}
+
+	file := os.Stdout
+	if filename != stdinName {
+		var err error
+		file, err = os.Create(filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	if filename != stdinName {
+		return file.Close()
+	}
+	return nil
+}
+
+// readMetrics loads a document written by -metrics, for -baseline.
+func readMetrics(filename string) (*jsonMetrics, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var doc jsonMetrics
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: not a -metrics file: %w", filename, err)
+	}
+	if doc.ExecutionTimeMS == 0 {
+		return nil, fmt.Errorf("%s: not a -metrics file: no execution_time_ms", filename)
+	}
+	return &doc, nil
+}
+
+// medianTime is the time compared with a baseline: the median of the runs
+// under -repeat, which one slow run cannot skew, or the only run's time.
+func medianTime(runTimes []float64, single float64) float64 {
+	if len(runTimes) > 1 {
+		_, median, _ := timingSummary(runTimes)
+		return median
+	}
+	return single
+}
+
+// writeBaseline prints how the run measured in a compares with the
+// -baseline metrics, such as "12.0% faster" and "8.0% less".
+func writeBaseline(w io.Writer, cfg config, a *analysis) {
+	base := cfg.baseline
+	fmt.Fprintf(w, "\n=== Compared with Baseline %s ===\n", cfg.baseName)
+	now, then := medianTime(a.runTimes, a.executionTime), medianTime(base.RunTimesMS, base.ExecutionTimeMS)
+	fmt.Fprintf(w, "Execution time:  %s (%.2f ms vs %.2f ms)\n", change(now, then, "faster", "slower"), now, then)
+	fmt.Fprintf(w, "Allocated:       %s (%.2f MB vs %.2f MB)\n",
+		change(float64(a.memory.allocated), float64(base.AllocatedBytes), "less", "more"),
+		float64(a.memory.allocated)/(1024.0*1024.0), float64(base.AllocatedBytes)/(1024.0*1024.0))
+	fmt.Fprintf(w, "Allocations:     %s (%s vs %s)\n",
+		change(float64(a.memory.mallocs), float64(base.Allocations), "fewer", "more"),
+		formatNumber(int64(a.memory.mallocs)), formatNumber(int64(base.Allocations)))
+	if a.totalWords != base.TotalWords || a.size.bytes != base.FileSizeBytes {
+		fmt.Fprintf(w, "Warning: the baseline counted %s words in %s bytes and this run %s words in %s bytes, "+
+			"so the inputs or options differ\n", formatNumber(base.TotalWords), formatNumber(base.FileSizeBytes),
+			formatNumber(a.totalWords), formatNumber(a.size.bytes))
+	}
+	if base.GoVersion != runtime.Version() || base.GOMAXPROCS != runtime.GOMAXPROCS(0) || base.Workers != cfg.workers {
+		fmt.Fprintf(w, "Baseline setup: %s, GOMAXPROCS %d, %d workers\n", base.GoVersion, base.GOMAXPROCS, base.Workers)
+	}
+}
+
+// checkBaseline returns an error if the run measured in a is more than
+// -max-slowdown percent slower than the -baseline run, so that scripts can
+// fail on a regression.
+func checkBaseline(cfg config, a *analysis) error {
+	if cfg.baseline == nil || cfg.maxSlow == 0 {
+		return nil
+	}
+	now, then := medianTime(a.runTimes, a.executionTime), medianTime(cfg.baseline.RunTimesMS, cfg.baseline.ExecutionTimeMS)
+	if pct := (now - then) / then * 100; pct > cfg.maxSlow {
+		return fmt.Errorf("%.1f%% slower than the baseline %s, past -max-slowdown %g%%", pct, cfg.baseName, cfg.maxSlow)
+	}
+	return nil
+}
+
+// change describes now relative to then as a percentage of then, with
+// down naming a decrease and up an increase.
+func change(now, then float64, down, up string) string {
+	if then == 0 {
+		return "no baseline value"
+	}
+	pct := (now - then) / then * 100
+	switch {
+	case math.Abs(pct) < 0.05:
+		return "no change"
+	case pct < 0:
+		return fmt.Sprintf("%.1f%% %s", -pct, down)
+	}
+	return fmt.Sprintf("%.1f%% %s", pct, up)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMetricsRoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "metrics.json")
+	a := &analysis{
+		filenames:     []string{"a.txt"},
+		totalWords:    120,
+		uniqueWords:   40,
+		executionTime: 12.5,
+		runTimes:      []float64{14, 12.5, 13},
+		memory:        memoryStats{allocated: 4096, mallocs: 32},
+	}
+	if err := writeMetrics(filename, testConfig(), a); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := readMetrics(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.TotalWords != 120 || doc.UniqueWords != 40 || doc.AllocatedBytes != 4096 || doc.Allocations != 32 {
+		t.Errorf("read back %+v", doc)
+	}
+	if got := medianTime(doc.RunTimesMS, doc.ExecutionTimeMS); got != 13 {
+		t.Errorf("median run time = %v, want 13", got)
+	}
+}
+
+func TestMetricsUnwritable(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "missing", "metrics.json")
+	if err := writeMetrics(filename, testConfig(), &analysis{}); err == nil {
+		t.Error("writeMetrics into a missing directory succeeded")
+	}
+}
+
+func TestCheckBaseline(t *testing.T) {
+	cfg := testConfig()
+	cfg.baseline, cfg.baseName = &jsonMetrics{ExecutionTimeMS: 100, RunTimesMS: []float64{90, 100, 110}}, "base.json"
+	for _, tt := range []struct {
+		maxSlow  float64
+		runTimes []float64
+		fail     bool
+	}{
+		{0, []float64{500, 500, 500}, false},
+		{10, []float64{105, 109, 300}, false},
+		{10, []float64{105, 111, 300}, true},
+		{10, []float64{50, 60, 70}, false},
+	} {
+		cfg.maxSlow = tt.maxSlow
+		err := checkBaseline(cfg, &analysis{runTimes: tt.runTimes, executionTime: tt.runTimes[2]})
+		if (err != nil) != tt.fail {
+			t.Errorf("-max-slowdown %g with runs %v: error %v, want failure %v", tt.maxSlow, tt.runTimes, err, tt.fail)
+		}
+		if err != nil && !strings.Contains(err.Error(), "11.0% slower than the baseline base.json") {
+			t.Errorf("error %q does not name the slowdown and baseline", err)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// mapFile is not implemented on this platform; -mmap falls back to
+// buffered reads.
+func mapFile(file *os.File) ([]byte, func() error, error) {
+	return nil, nil, errors.New("mmap is not supported on this platform")
+}
@@ -0,0 +1,596 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/transform"
+)
+
+// Results file formats accepted by -format.
+const (
+	formatText  = "text"
+	formatJSON  = "json"
+	formatJSONL = "jsonl"
+	formatCSV   = "csv"
+	formatTSV   = "tsv"
+)
+
+// formatExtensions maps each results file format to its file extension.
+var formatExtensions = map[string]string{
+	formatText:  ".txt",
+	formatJSON:  ".json",
+	formatJSONL: ".jsonl",
+	formatCSV:   ".csv",
+	formatTSV:   ".tsv",
+}
+
+// Word orders accepted by -sort.
+const (
+	sortCount     = "count"
+	sortCountAsc  = "count-asc"
+	sortAlpha     = "alpha"
+	sortLength    = "length"
+	sortInsertion = "insertion"
+)
+
+// sortOrders maps each -sort order to its comparator. Insertion order has
+// none: words are listed as Result.FirstSeen has them.
+var sortOrders = map[string]func(a, b wordfreq.WordCount) bool{
+	sortCount:     wordfreq.ByCount,
+	sortCountAsc:  wordfreq.ByCountAsc,
+	sortAlpha:     wordfreq.ByWord,
+	sortLength:    wordfreq.ByLength,
+	sortInsertion: nil,
+}
+
+// listTitle describes the first n words (0 = all) of a list in the given
+// -sort order.
+func listTitle(order string, n int) string {
+	switch order {
+	case sortCountAsc:
+		if n == 0 {
+			return "All Words by Ascending Frequency"
+		}
+		return fmt.Sprintf("%d Least Frequent Words", n)
+	case sortAlpha:
+		if n == 0 {
+			return "All Words Alphabetically"
+		}
+		return fmt.Sprintf("First %d Words Alphabetically", n)
+	case sortLength:
+		if n == 0 {
+			return "All Words by Length"
+		}
+		return fmt.Sprintf("%d Longest Words", n)
+	case sortInsertion:
+		if n == 0 {
+			return "All Words in Order of First Appearance"
+		}
+		return fmt.Sprintf("First %d Words to Appear", n)
+	}
+	if n == 0 {
+		return "All Words by Frequency"
+	}
+	return fmt.Sprintf("Top %d Most Frequent Words", n)
+}
+
+// report holds everything written to the results file.
+type report struct {
+	filenames     []string
+	sorted        []wordfreq.WordCount // in -sort order
+	order         string
+	bottom        []wordfreq.WordCount // least frequent words, if requested
+	totalWords    int64
+	uniqueWords   int
+	executionTime float64
+	top           int
+	precision     int  // decimal places of percentages
+	stable        bool // omit the generation time and execution time
+	compact       bool // text word lists as unpadded "word count" lines
+	notes         []string
+	lengths       []int64 // word-length histogram, if requested
+	coverage      []coveragePoint
+	perLength     []lengthGroup // -top-per-length groups, if requested
+	variants      map[string][]wordfreq.WordCount
+	freqHist      []freqBucket
+	unknown       []wordfreq.WordCount // -dict words not in the dictionary, by count
+	checked       bool                 // -dict was given, so unknown is meaningful even if empty
+	charset       encoding.Encoding    // -output-encoding of the file; nil for UTF-8
+}
+
+// bottomWords returns the n least frequent words from a slice ordered by
+// descending count, rarest first. Words with equal counts stay in the order
+// of the slice, matching the tie-break used for the top list.
+func bottomWords(sorted []wordfreq.WordCount, n int) []wordfreq.WordCount {
+	out := make([]wordfreq.WordCount, 0, min(n, len(sorted)))
+	end := len(sorted)
+	for end > 0 && len(out) < n {
+		start := end - 1
+		for start > 0 && sorted[start-1].Count == sorted[end-1].Count {
+			start--
+		}
+		group := sorted[start:end]
+		if need := n - len(out); len(group) > need {
+			group = group[:need]
+		}
+		out = append(out, group...)
+		end = start
+	}
+	return out
+}
+
+// percentage returns the share of all words accounted for by count.
+func (r *report) percentage(count int) float64 {
+	return share(int64(count), r.totalWords)
+}
+
+// share returns count as a percentage of total, or 0 if total is 0.
+func share(count, total int64) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) * 100.0 / float64(total)
+}
+
+// histogramTotal returns the number of words in the word-length histogram
+// lengths, which its percentages are shares of. Under -ngram that is not
+// the total of grams.
+func histogramTotal(lengths []int64) int64 {
+	var total int64
+	for _, count := range lengths {
+		total += count
+	}
+	return total
+}
+
+// inputNames returns the display names of the inputs, comma separated.
+func (r *report) inputNames() string {
+	names := make([]string, len(r.filenames))
+	for i, filename := range r.filenames {
+		names[i] = displayName(filename)
+	}
+	return strings.Join(names, ", ")
+}
+
+// resultsName derives the default results file name from the inputs by
+// replacing the final extension of the input with _go_results and the
+// extension of format: book.txt and book.txt.gz become
+// book_go_results.txt, a.b.txt becomes a.b_go_results.txt, and names
+// without an extension (book, x, dir.v2/book) simply gain the suffix.
+func resultsName(filenames []string, format string) string {
+	ext := formatExtensions[format]
+	if len(filenames) > 1 {
+		return "combined_go_results" + ext
+	}
+
+	filename := strings.TrimSuffix(filenames[0], ".gz")
+	if filename == stdinName {
+		return "stdin_go_results" + ext
+	}
+	// A dotfile such as .hidden keeps its name rather than losing it all.
+	if base := strings.TrimSuffix(filename, filepath.Ext(filename)); base != "" && !os.IsPathSeparator(base[len(base)-1]) {
+		filename = base
+	}
+	return filename + "_go_results" + ext
+}
+
+// writeOutputFile writes the results file to outputFilename, or to the
+// name derived by resultsName when outputFilename is empty. An
+// outputFilename of "-" writes the results to stdout. With compress the
+// results are gzipped and a file name gains .gz unless it already ends
+// in it. The results are transcoded to rep.charset, if set, before they
+// are compressed. The file name is reported on con.
+func writeOutputFile(con io.Writer, format, outputFilename string, compress bool, rep report) error {
+	if outputFilename == "" {
+		outputFilename = resultsName(rep.filenames, format)
+	}
+	if compress && outputFilename != stdinName && !strings.HasSuffix(outputFilename, ".gz") {
+		outputFilename += ".gz"
+	}
+
+	file := os.Stdout
+	if outputFilename != stdinName {
+		var err error
+		file, err = os.Create(outputFilename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+	}
+
+	// Layers are closed innermost first: the buffer is flushed into the
+	// encoder, which flushes into the gzip stream, whose Close writes the
+	// trailer, before the file closes.
+	var out io.Writer = file
+	var zw *gzip.Writer
+	if compress {
+		zw = gzip.NewWriter(file)
+		out = zw
+	}
+	var ew *transform.Writer
+	if rep.charset != nil {
+		ew = transform.NewWriter(out, encoding.ReplaceUnsupported(rep.charset.NewEncoder()))
+		out = ew
+	}
+	writer := bufio.NewWriterSize(out, 32*1024)
+
+	var err error
+	switch format {
+	case formatJSON:
+		err = writeJSON(writer, &rep)
+	case formatJSONL:
+		err = writeJSONL(writer, &rep)
+	case formatCSV:
+		err = writeCSV(writer, &rep)
+	case formatTSV:
+		err = writeTSV(writer, &rep)
+	default:
+		writeText(writer, &rep)
+	}
+	if err != nil {
+		return err
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	if ew != nil {
+		if err := ew.Close(); err != nil {
+			return err
+		}
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return err
+		}
+	}
+	if outputFilename != stdinName {
+		if err := file.Close(); err != nil {
+			return err
+		}
+		fmt.Fprintf(con, "\nResults written to: %s\n", outputFilename)
+	}
+	return nil
+}
+
+func writeText(w io.Writer, rep *report) {
+	fmt.Fprintf(w, "Word Frequency Analysis - Go Implementation\n")
+	if len(rep.filenames) > 1 {
+		fmt.Fprintf(w, "Input files: %s\n", rep.inputNames())
+	} else {
+		fmt.Fprintf(w, "Input file: %s\n", rep.inputNames())
+	}
+	if !rep.stable {
+		fmt.Fprintf(w, "Generated: %s\n", time.Now().Format("2006-01-02 15:04:05"))
+		fmt.Fprintf(w, "Execution time: %.2f ms\n", rep.executionTime)
+	}
+	for _, note := range rep.notes {
+		fmt.Fprintf(w, "%s\n", note)
+	}
+	fmt.Fprintf(w, "\n")
+	fmt.Fprintf(w, "Total words: %s\n", formatNumber(rep.totalWords))
+	fmt.Fprintf(w, "Unique words: %s\n\n", formatNumber(int64(rep.uniqueWords)))
+	fmt.Fprintf(w, "%s:\n", listTitle(rep.order, rep.top))
+	limit := topLimit(rep.top, len(rep.sorted))
+	writeWordTable(w, rep, rep.sorted[:limit])
+
+	if rep.bottom != nil {
+		fmt.Fprintf(w, "\nBottom %d Least Frequent Words:\n", len(rep.bottom))
+		writeWordTable(w, rep, rep.bottom)
+	}
+
+	if rep.lengths != nil {
+		fmt.Fprintf(w, "\nWord Length Distribution:\n")
+		writeLengths(w, rep.lengths, rep.precision)
+	}
+
+	if rep.coverage != nil {
+		fmt.Fprintf(w, "\nVocabulary Coverage:\n")
+		writeCoverage(w, rep.coverage, rep.uniqueWords)
+	}
+
+	if rep.freqHist != nil {
+		fmt.Fprintf(w, "\nFrequency Distribution:\n")
+		writeFreqHistogram(w, rep.freqHist, rep.uniqueWords)
+	}
+
+	if len(rep.variants) > 0 {
+		fmt.Fprintf(w, "\nGrouped Variants:\n")
+		writeVariants(w, rep.sorted[:limit], rep.variants)
+	}
+
+	for _, g := range rep.perLength {
+		fmt.Fprintf(w, "\nMost Frequent Words of Length %d:\n", g.length)
+		writeWordTable(w, rep, g.words)
+	}
+
+	if rep.checked {
+		fmt.Fprintf(w, "\nWords Not in Dictionary:\n")
+		fmt.Fprintf(w, "%s\n", unknownSummary(rep.unknown, rep.uniqueWords, rep.totalWords))
+		writeWordTable(w, rep, rep.unknown[:topLimit(rep.top, len(rep.unknown))])
+	}
+}
+
+// percentWidth returns the width of a percentage printed with the given
+// number of decimals, padded to at least min so default tables keep their
+// layout.
+func percentWidth(min, precision int) int {
+	return max(min, len("100.")+precision)
+}
+
+// writeWordTable prints a ranked table of words with their percentages.
+// With -full the table can run to millions of rows, so each row is built
+// by hand rather than with fmt; the layout is that of
+// "%4d  %-15s %9s %*.*f%%\n".
+func writeWordTable(w io.Writer, rep *report, words []wordfreq.WordCount) {
+	if rep.compact {
+		writeCompactList(w, words)
+		return
+	}
+	width := percentWidth(10, rep.precision)
+	fmt.Fprintf(w, "Rank  Word            Count     %*s\n", width, "Percentage")
+	fmt.Fprintf(w, "----  --------------- --------- %s\n", strings.Repeat("-", width))
+	line := make([]byte, 0, 128)
+	for i, wc := range words {
+		line = appendPadded(line[:0], strconv.AppendInt(nil, int64(i+1), 10), 4, false)
+		line = append(line, "  "...)
+		line = appendPadded(line, []byte(wc.Word), 15, true)
+		line = append(line, ' ')
+		line = appendPadded(line, appendNumber(nil, int64(wc.Count)), 9, false)
+		line = append(line, ' ')
+		pct := strconv.AppendFloat(nil, rep.percentage(wc.Count), 'f', rep.precision, 64)
+		line = appendPadded(line, pct, width, false)
+		line = append(line, "%\n"...)
+		if _, err := w.Write(line); err != nil {
+			return
+		}
+	}
+}
+
+// compactHeader heads a -compact word list in place of the table header.
+const compactHeader = "Word Count"
+
+// writeCompactList prints words for -compact: one "word count" line each,
+// with no rank, padding, digit grouping or percentage, which makes full
+// dumps a fraction of the size of the table.
+func writeCompactList(w io.Writer, words []wordfreq.WordCount) {
+	fmt.Fprintln(w, compactHeader)
+	line := make([]byte, 0, 128)
+	for _, wc := range words {
+		line = append(line[:0], wc.Word...)
+		line = append(line, ' ')
+		line = strconv.AppendInt(line, int64(wc.Count), 10)
+		line = append(line, '\n')
+		if _, err := w.Write(line); err != nil {
+			return
+		}
+	}
+}
+
+// appendPadded appends field to dst padded with spaces to width
+// characters, on the right when left is set and on the left otherwise.
+// Like fmt, the width counts UTF-8 characters rather than bytes.
+func appendPadded(dst, field []byte, width int, left bool) []byte {
+	pad := width - utf8.RuneCount(field)
+	if left {
+		dst = append(dst, field...)
+	}
+	for ; pad > 0; pad-- {
+		dst = append(dst, ' ')
+	}
+	if !left {
+		dst = append(dst, field...)
+	}
+	return dst
+}
+
+// appendNumber appends n with thousands separators, as formatNumber.
+func appendNumber(dst []byte, n int64) []byte {
+	var digits [20]byte
+	str := strconv.AppendInt(digits[:0], n, 10)
+	for i, digit := range str {
+		if i > 0 && (len(str)-i)%3 == 0 {
+			dst = append(dst, ',')
+		}
+		dst = append(dst, digit)
+	}
+	return dst
+}
+
+// writeLengths prints the non-empty buckets of a word-length histogram.
+func writeLengths(w io.Writer, lengths []int64, precision int) {
+	total := histogramTotal(lengths)
+	width := percentWidth(9, precision)
+	fmt.Fprintf(w, "Length  Count     %*s\n", width+1, "Percentage")
+	fmt.Fprintf(w, "------  --------- %s\n", strings.Repeat("-", width+1))
+	for n, count := range lengths {
+		if count == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%6d  %9s %*.*f%%\n", n, formatNumber(count), width, precision, share(count, total))
+	}
+}
+
+// jsonReport is the document written by -format json.
+type jsonReport struct {
+	InputFile       string          `json:"input_file"`
+	Generated       string          `json:"generated,omitempty"`
+	ExecutionTimeMS float64         `json:"execution_time_ms,omitempty"`
+	TotalWords      int64           `json:"total_words"`
+	UniqueWords     int             `json:"unique_words"`
+	Notes           []string        `json:"notes,omitempty"`
+	Words           []jsonEntry     `json:"words"`
+	Bottom          []jsonEntry     `json:"bottom,omitempty"`
+	Lengths         []jsonBin       `json:"lengths,omitempty"`
+	Coverage        []coveragePoint `json:"coverage,omitempty"`
+	TopPerLength    []jsonGroup     `json:"top_per_length,omitempty"`
+	NotInDictionary []jsonEntry     `json:"not_in_dictionary,omitempty"`
+	FreqHist        []jsonFreqBin   `json:"freq_hist,omitempty"`
+	// Variants maps each listed -phonetic or -stem group to the spellings
+	// counted under it, most frequent first.
+	Variants map[string][]jsonlEntry `json:"variants,omitempty"`
+}
+
+// jsonGroup is the most frequent words of one length.
+type jsonGroup struct {
+	Length int         `json:"length"`
+	Words  []jsonEntry `json:"words"`
+}
+
+// jsonBin is one bucket of the word-length histogram.
+type jsonBin struct {
+	Length     int     `json:"length"`
+	Count      int64   `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// jsonFreqBin is one bucket of the -freq-hist histogram: the unique words
+// occurring from MinCount to MaxCount times.
+type jsonFreqBin struct {
+	MinCount    int     `json:"min_count"`
+	MaxCount    int     `json:"max_count"`
+	UniqueWords int     `json:"unique_words"`
+	Percentage  float64 `json:"percentage"`
+}
+
+type jsonEntry struct {
+	Word       string  `json:"word"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+func writeJSON(w io.Writer, rep *report) error {
+	limit := topLimit(rep.top, len(rep.sorted))
+
+	doc := jsonReport{
+		InputFile:       rep.inputNames(),
+		Generated:       time.Now().Format(time.RFC3339),
+		ExecutionTimeMS: rep.executionTime,
+		TotalWords:      rep.totalWords,
+		UniqueWords:     rep.uniqueWords,
+		Notes:           rep.notes,
+		Words:           make([]jsonEntry, 0, limit),
+		Coverage:        rep.coverage,
+	}
+	if rep.stable {
+		doc.Generated, doc.ExecutionTimeMS = "", 0
+	}
+	for _, wc := range rep.sorted[:limit] {
+		doc.Words = append(doc.Words, jsonEntry{wc.Word, wc.Count, rep.percentage(wc.Count)})
+		if spellings, ok := rep.variants[wc.Word]; ok {
+			if doc.Variants == nil {
+				doc.Variants = make(map[string][]jsonlEntry)
+			}
+			for _, s := range spellings {
+				doc.Variants[wc.Word] = append(doc.Variants[wc.Word], jsonlEntry{s.Word, s.Count})
+			}
+		}
+	}
+
+	for _, wc := range rep.bottom {
+		doc.Bottom = append(doc.Bottom, jsonEntry{wc.Word, wc.Count, rep.percentage(wc.Count)})
+	}
+	for _, g := range rep.perLength {
+		group := jsonGroup{Length: g.length}
+		for _, wc := range g.words {
+			group.Words = append(group.Words, jsonEntry{wc.Word, wc.Count, rep.percentage(wc.Count)})
+		}
+		doc.TopPerLength = append(doc.TopPerLength, group)
+	}
+	for _, wc := range rep.unknown[:topLimit(rep.top, len(rep.unknown))] {
+		doc.NotInDictionary = append(doc.NotInDictionary, jsonEntry{wc.Word, wc.Count, rep.percentage(wc.Count)})
+	}
+	for _, b := range rep.freqHist {
+		doc.FreqHist = append(doc.FreqHist, jsonFreqBin{b.lo, b.hi, b.words, share(int64(b.words), int64(rep.uniqueWords))})
+	}
+	histogram := histogramTotal(rep.lengths)
+	for n, count := range rep.lengths {
+		if count != 0 {
+			doc.Lengths = append(doc.Lengths, jsonBin{n, count, share(count, histogram)})
+		}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// jsonlEntry is one line of -format jsonl.
+type jsonlEntry struct {
+	Word  string `json:"word"`
+	Count int    `json:"count"`
+}
+
+// writeJSONL writes one {"word":...,"count":...} object per line in
+// sorted order. Each line is encoded on its own, so nothing beyond the
+// sorted slice is held in memory.
+func writeJSONL(w io.Writer, rep *report) error {
+	enc := json.NewEncoder(w)
+	limit := topLimit(rep.top, len(rep.sorted))
+	for _, wc := range rep.sorted[:limit] {
+		if err := enc.Encode(jsonlEntry{wc.Word, wc.Count}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeCSV writes rank,word,count,percentage rows after a header row.
+// encoding/csv quotes any word containing a comma or quote.
+func writeCSV(w io.Writer, rep *report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"rank", "word", "count", "percentage"}); err != nil {
+		return err
+	}
+
+	limit := topLimit(rep.top, len(rep.sorted))
+	for i, wc := range rep.sorted[:limit] {
+		record := []string{
+			strconv.Itoa(i + 1),
+			wc.Word,
+			strconv.Itoa(wc.Count),
+			strconv.FormatFloat(rep.percentage(wc.Count), 'f', rep.precision, 64),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeTSV writes word, count and percentage separated by tabs, one word
+// per line after a header line, with no padding or quoting so the file
+// can be read with cut or awk.
+func writeTSV(w io.Writer, rep *report) error {
+	if _, err := io.WriteString(w, "word\tcount\tpercentage\n"); err != nil {
+		return err
+	}
+	line := make([]byte, 0, 128)
+	limit := topLimit(rep.top, len(rep.sorted))
+	for _, wc := range rep.sorted[:limit] {
+		line = append(line[:0], wc.Word...)
+		line = append(line, '\t')
+		line = strconv.AppendInt(line, int64(wc.Count), 10)
+		line = append(line, '\t')
+		line = strconv.AppendFloat(line, rep.percentage(wc.Count), 'f', rep.precision, 64)
+		line = append(line, '\n')
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResultsName(t *testing.T) {
+	tests := []struct {
+		filenames []string
+		format    string
+		want      string
+	}{
+		{[]string{"book"}, formatText, "book_go_results.txt"},
+		{[]string{"x"}, formatText, "x_go_results.txt"},
+		{[]string{"a.b.txt"}, formatText, "a.b_go_results.txt"},
+		{[]string{"book.txt"}, formatText, "book_go_results.txt"},
+		{[]string{"book.txt.gz"}, formatText, "book_go_results.txt"},
+		{[]string{"book.txt"}, formatJSON, "book_go_results.json"},
+		{[]string{"book.txt"}, formatCSV, "book_go_results.csv"},
+		{[]string{".hidden"}, formatText, ".hidden_go_results.txt"},
+		{[]string{filepath.Join("dir.v2", "book")}, formatText, filepath.Join("dir.v2", "book") + "_go_results.txt"},
+		{[]string{filepath.Join("dir", ".hidden")}, formatText, filepath.Join("dir", ".hidden") + "_go_results.txt"},
+		{[]string{stdinName}, formatText, "stdin_go_results.txt"},
+		{[]string{"a.txt", "b.txt"}, formatTSV, "combined_go_results.tsv"},
+	}
+	for _, tt := range tests {
+		if got := resultsName(tt.filenames, tt.format); got != tt.want {
+			t.Errorf("resultsName(%q, %q) = %q, want %q", tt.filenames, tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestLengthPercentages(t *testing.T) {
+	// Under -ngram 2 a one-word input has no grams but one word of length 3.
+	rep := &report{filenames: []string{"one.txt"}, lengths: []int64{0, 0, 1, 3}, precision: 2}
+
+	var text bytes.Buffer
+	writeLengths(&text, rep.lengths, rep.precision)
+	if !strings.Contains(text.String(), "75.00%") || strings.Contains(text.String(), "Inf") {
+		t.Errorf("writeLengths:\n%s\nwant shares of the 4 words in the histogram", text.String())
+	}
+
+	var doc bytes.Buffer
+	if err := writeJSON(&doc, rep); err != nil {
+		t.Fatalf("writeJSON: %v", err)
+	}
+	var got jsonReport
+	if err := json.Unmarshal(doc.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	want := []jsonBin{{2, 1, 25}, {3, 3, 75}}
+	if !slices.Equal(got.Lengths, want) {
+		t.Errorf("JSON lengths = %v, want %v", got.Lengths, want)
+	}
+
+	if got := share(5, 0); got != 0 {
+		t.Errorf("share(5, 0) = %v, want 0", got)
+	}
+}
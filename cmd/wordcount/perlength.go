@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+// lengthGroup is the most frequent words of one length.
+type lengthGroup struct {
+	length int
+	words  []wordfreq.WordCount
+}
+
+// topPerLength buckets words, which must be ordered by descending count, by
+// length and keeps the first n of each bucket, shortest length first.
+// Lengths are in characters when runes is set and in bytes otherwise.
+func topPerLength(words []wordfreq.WordCount, n int, runes bool) []lengthGroup {
+	byLength := make(map[int]int) // length -> index in groups
+	var groups []lengthGroup
+	for _, wc := range words {
+		length := len(wc.Word)
+		if runes {
+			length = utf8.RuneCountInString(wc.Word)
+		}
+		i, ok := byLength[length]
+		if !ok {
+			i = len(groups)
+			byLength[length] = i
+			groups = append(groups, lengthGroup{length: length})
+		}
+		if len(groups[i].words) < n {
+			groups[i].words = append(groups[i].words, wc)
+		}
+	}
+	slices.SortFunc(groups, func(a, b lengthGroup) int { return a.length - b.length })
+	return groups
+}
+
+// writePerLength prints each group as a short ranked list.
+func writePerLength(w io.Writer, groups []lengthGroup) {
+	for i, g := range groups {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "Length %d:\n", g.length)
+		for rank, wc := range g.words {
+			fmt.Fprintf(w, "%2d. %-15s %9s\n", rank+1, wc.Word, formatNumber(int64(wc.Count)))
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// progressInterval is how often -progress refreshes its status line.
+const progressInterval = 500 * time.Millisecond
+
+// startProgress prints bytes read so far, a percentage and an ETA to
+// stderr every progressInterval until the returned stop function is called.
+// total is the expected input size, or 0 if unknown (stdin), in which case
+// only the byte count is shown.
+func startProgress(r *countingReader, total int64) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	start := time.Now()
+
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(progressInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				printProgress(r.bytesRead(), total, time.Since(start))
+				fmt.Fprintln(os.Stderr)
+				return
+			case <-ticker.C:
+				printProgress(r.bytesRead(), total, time.Since(start))
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
+
+func printProgress(read, total int64, elapsed time.Duration) {
+	readMB := float64(read) / (1024.0 * 1024.0)
+	if total <= 0 {
+		fmt.Fprintf(os.Stderr, "\rProgress: %.1f MB read", readMB)
+		return
+	}
+
+	fraction := float64(read) / float64(total)
+	eta := "--"
+	if read > 0 && read < total {
+		remaining := time.Duration(float64(elapsed) * (1 - fraction) / fraction)
+		eta = remaining.Round(time.Second).String()
+	} else if read >= total {
+		eta = "0s"
+	}
+	fmt.Fprintf(os.Stderr, "\rProgress: %5.1f%% (%.1f / %.1f MB), ETA %-8s",
+		fraction*100, readMB, float64(total)/(1024.0*1024.0), eta)
+}
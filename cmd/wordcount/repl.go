@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+const replHelp = `Commands:
+  WORD ...         show the count and rank of each word
+  top [N]          list the N most highly ranked words (default 10)
+  prefix P [N]     list the first N words starting with P (default 10)
+  help             show this help
+  quit             leave (as does end of input)
+`
+
+// repl answers queries about the counts in a, one line at a time from in,
+// until quit or the end of in. Answers go to out and the prompt to
+// prompt, so the answers can be piped on their own. Query words are
+// normalized with opts so they match the counted keys. Ranks follow the
+// -sort order of a.sorted, which must hold every word.
+func repl(in io.Reader, out, prompt io.Writer, a *analysis, opts wordfreq.Options) error {
+	ranks := make(map[string]int, len(a.sorted))
+	for i, wc := range a.sorted {
+		ranks[wc.Word] = i
+	}
+	buf := make([]byte, 0, wordfreq.MaxWordLength)
+	normalize := func(s string) string {
+		if word, _, ok := wordfreq.ExtractWord([]byte(s), 0, buf, opts); ok {
+			return string(word)
+		}
+		return s
+	}
+
+	w := bufio.NewWriter(out)
+	fmt.Fprintf(prompt, "\nType a word to look it up, or help for the commands.\n")
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(prompt, "> ")
+		if !scanner.Scan() {
+			break
+		}
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "quit", "exit":
+			return w.Flush()
+		case "help":
+			fmt.Fprint(w, replHelp)
+		case "top":
+			n, err := replCount(fields[1:])
+			if err != nil {
+				fmt.Fprintf(w, "top: %v\n", err)
+				break
+			}
+			for i, wc := range a.sorted[:min(n, len(a.sorted))] {
+				fmt.Fprintf(w, "%4d. %-15s %9s\n", i+1, wc.Word, formatNumber(int64(wc.Count)))
+			}
+		case "prefix":
+			if len(fields) < 2 {
+				fmt.Fprintf(w, "prefix: missing prefix\n")
+				break
+			}
+			n, err := replCount(fields[2:])
+			if err != nil {
+				fmt.Fprintf(w, "prefix: %v\n", err)
+				break
+			}
+			prefix := normalize(fields[1])
+			found := 0
+			for i, wc := range a.sorted {
+				if !strings.HasPrefix(wc.Word, prefix) {
+					continue
+				}
+				if found < n {
+					fmt.Fprintf(w, "%4d. %-15s %9s\n", i+1, wc.Word, formatNumber(int64(wc.Count)))
+				}
+				found++
+			}
+			if found > n {
+				fmt.Fprintf(w, "(%s more)\n", formatNumber(int64(found-n)))
+			} else if found == 0 {
+				fmt.Fprintf(w, "no words start with %q\n", prefix)
+			}
+		default:
+			for _, field := range fields {
+				word := normalize(field)
+				i, ok := ranks[word]
+				if !ok {
+					fmt.Fprintf(w, "%s: not found\n", word)
+					continue
+				}
+				count := a.sorted[i].Count
+				fmt.Fprintf(w, "%s: %s (rank %s of %s, %.2f%%)\n", word, formatNumber(int64(count)),
+					formatNumber(int64(i+1)), formatNumber(int64(len(a.sorted))),
+					share(int64(count), a.totalWords))
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+// replCount parses the optional count argument of a command.
+func replCount(args []string) (int, error) {
+	if len(args) == 0 {
+		return 10, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid count %q", args[0])
+	}
+	return n, nil
+}
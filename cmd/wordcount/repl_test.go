@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/KrishRVH/word-parser-performance/wordfreq"
+)
+
+func TestREPL(t *testing.T) {
+	a := &analysis{
+		sorted:     wordfreq.Sort(map[string]int{"the": 5, "cat": 3, "car": 1, "dog": 1}),
+		totalWords: 10,
+	}
+	in := strings.NewReader("The cats\ntop 2\nprefix CA 1\nprefix x\ntop zero\nquit\nthe\n")
+	var out bytes.Buffer
+	if err := repl(in, &out, io.Discard, a, wordfreq.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	// Queries are case-folded like the counted words, and nothing after
+	// quit is answered.
+	want := `the: 5 (rank 1 of 4, 50.00%)
+cats: not found
+   1. the                     5
+   2. cat                     3
+   2. cat                     3
+(1 more)
+no words start with "x"
+top: invalid count "zero"
+`
+	if out.String() != want {
+		t.Errorf("answers:\n%s\nwant:\n%s", out.String(), want)
+	}
+}
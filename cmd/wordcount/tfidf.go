@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// termScore is the TF-IDF score of one word in one document.
+type termScore struct {
+	word  string
+	count int
+	score float64
+}
+
+// tfidf scores every word of each document in m as tf * log(N/df), where
+// tf is the word's share of the document's words, N the number of
+// documents and df the number of documents containing the word. Words in
+// every document score 0 and are left out. Each list is ordered by
+// descending score, then alphabetically.
+func tfidf(m *termMatrix) [][]termScore {
+	df := make(map[string]int)
+	for _, counts := range m.counts {
+		for word := range counts {
+			df[word]++
+		}
+	}
+	n := float64(len(m.counts))
+	scores := make([][]termScore, len(m.counts))
+	for i, counts := range m.counts {
+		for word, count := range counts {
+			idf := math.Log(n / float64(df[word]))
+			if idf == 0 {
+				continue
+			}
+			tf := float64(count) / float64(m.totals[i])
+			scores[i] = append(scores[i], termScore{word, count, tf * idf})
+		}
+		list := scores[i]
+		sort.Slice(list, func(a, b int) bool {
+			if list[a].score != list[b].score {
+				return list[a].score > list[b].score
+			}
+			return list[a].word < list[b].word
+		})
+	}
+	return scores
+}
+
+// runTFIDF treats each of filenames as a document and reports the words
+// that best distinguish each one from the others. The report goes to the
+// console, and also to -o if given.
+func runTFIDF(ctx context.Context, cfg config, filenames []string) error {
+	con := cfg.console()
+	m, err := countDocuments(ctx, cfg, filenames, 0)
+	if err != nil {
+		return err
+	}
+	scores := tfidf(m)
+	writeTFIDF(con, cfg.consoleTop, m, scores)
+
+	if cfg.output == "" {
+		return nil
+	}
+	out := os.Stdout
+	if cfg.output != stdinName {
+		out, err = os.Create(cfg.output)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+	w := bufio.NewWriter(out)
+	writeTFIDF(w, cfg.fileTop, m, scores)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if cfg.output != stdinName {
+		fmt.Fprintf(con, "\nTF-IDF scores written to: %s\n", cfg.output)
+	}
+	return nil
+}
+
+// writeTFIDF prints the top-scoring words of each document with their
+// counts and scores.
+func writeTFIDF(w io.Writer, top int, m *termMatrix, scores [][]termScore) {
+	fmt.Fprintf(w, "TF-IDF over %d documents\n", len(m.docs))
+	for i, doc := range m.docs {
+		fmt.Fprintf(w, "\n=== Most Distinctive Words in %s (%s words) ===\n", doc, formatNumber(m.totals[i]))
+		fmt.Fprintf(w, "Rank  Word            Count     TF-IDF\n")
+		fmt.Fprintf(w, "----  --------------- --------- ----------\n")
+		for j, s := range scores[i][:topLimit(top, len(scores[i]))] {
+			fmt.Fprintf(w, "%4d  %-15s %9s %10.6f\n", j+1, s.word, formatNumber(int64(s.count)), s.score)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTFIDF(t *testing.T) {
+	m := &termMatrix{
+		docs: []string{"a", "b", "c"},
+		counts: []map[string]int{
+			{"the": 2, "cat": 2},
+			{"the": 1, "dog": 1, "cat": 1},
+			{"the": 3, "fish": 1},
+		},
+		totals: []int64{4, 3, 4},
+	}
+	want := [][]termScore{
+		{{"cat", 2, 0.5 * math.Log(1.5)}},
+		{{"dog", 1, math.Log(3) / 3}, {"cat", 1, math.Log(1.5) / 3}},
+		{{"fish", 1, 0.25 * math.Log(3)}},
+	}
+	got := tfidf(m)
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("document %s: scores %v, want %v", m.docs[i], got[i], want[i])
+		}
+		for j, w := range want[i] {
+			if g := got[i][j]; g.word != w.word || g.count != w.count || math.Abs(g.score-w.score) > 1e-12 {
+				t.Errorf("document %s, rank %d: %v, want %v", m.docs[i], j+1, g, w)
+			}
+		}
+	}
+}
+
+func TestRunTFIDF(t *testing.T) {
+	a := writeFile(t, "a.txt", []byte("the cat sat on the mat"))
+	b := writeFile(t, "b.txt", []byte("the dog sat"))
+	cfg := testConfig()
+	cfg.output = filepath.Join(t.TempDir(), "tfidf.txt")
+	if err := runTFIDF(context.Background(), cfg, []string{a, b}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(cfg.output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, inB, ok := strings.Cut(string(data), "=== Most Distinctive Words in "+b+" (3 words) ===")
+	if !ok {
+		t.Fatalf("report lacks the section for b.txt:\n%s", data)
+	}
+	// "the" and "sat" are in both documents, so only "dog" is listed.
+	if !strings.Contains(inB, " dog ") || strings.Contains(inB, " the ") || strings.Contains(inB, " sat ") {
+		t.Errorf("wrong words for b.txt:\n%s", inB)
+	}
+}
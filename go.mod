@@ -0,0 +1,5 @@
+module github.com/KrishRVH/word-parser-performance
+
+go 1.22
+
+require golang.org/x/text v0.22.0
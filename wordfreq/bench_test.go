@@ -0,0 +1,104 @@
+package wordfreq
+
+import (
+	"bytes"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// sampleSize is the size of the generated benchmark text.
+const sampleSize = 8 * 1024 * 1024 // 8MB
+
+// generateSample returns size bytes of pseudo-random text with a Zipf-like
+// vocabulary of mixed-case words, punctuation and line breaks. The seed is
+// fixed, so results are comparable between machines and commits.
+func generateSample(size int) []byte {
+	rng := rand.New(rand.NewSource(1))
+	vocab := make([]string, 5000)
+	for i := range vocab {
+		word := make([]byte, 1+rng.Intn(12))
+		for j := range word {
+			word[j] = byte('a' + rng.Intn(26))
+		}
+		if rng.Intn(10) == 0 {
+			word[0] -= 'a' - 'A'
+		}
+		vocab[i] = string(word)
+	}
+	zipf := rand.NewZipf(rng, 1.1, 1, uint64(len(vocab)-1))
+	separators := []string{" ", " ", " ", ", ", ". ", "\n", " -- ", "'s "}
+
+	var buf bytes.Buffer
+	buf.Grow(size + 64)
+	for buf.Len() < size {
+		buf.WriteString(vocab[zipf.Uint64()])
+		buf.WriteString(separators[rng.Intn(len(separators))])
+	}
+	return buf.Bytes()[:size]
+}
+
+// sample is the benchmark text, generated once.
+var sample = sync.OnceValue(func() []byte { return generateSample(sampleSize) })
+
+// benchmarkCount reports the throughput of counting data with fn.
+func benchmarkCount(b *testing.B, data []byte, fn func(data []byte) error) {
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := fn(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	for _, bm := range []struct {
+		name string
+		opts Options
+	}{
+		{"ascii", Options{}},
+		{"unicode", Options{Unicode: true}},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			benchmarkCount(b, sample(), func(data []byte) error {
+				_, _, err := Count(bytes.NewReader(data), bm.opts)
+				return err
+			})
+		})
+	}
+}
+
+// BenchmarkCountParallel compares per-worker tables merged at the end with
+// one sharded store shared by the workers.
+func BenchmarkCountParallel(b *testing.B) {
+	workers := runtime.GOMAXPROCS(0)
+	for _, bm := range []struct {
+		name string
+		hash Hash
+	}{
+		{"merge", HashFNV},
+		{"sharded", HashSharded},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			benchmarkCount(b, sample(), func(data []byte) error {
+				_, _, err := CountParallel(bytes.NewReader(data), Options{Hash: bm.hash}, workers)
+				return err
+			})
+		})
+	}
+}
+
+func BenchmarkSortWords(b *testing.B) {
+	counts, _, err := Count(bytes.NewReader(sample()), Options{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Sort(counts)
+	}
+}
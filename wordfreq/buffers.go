@@ -0,0 +1,65 @@
+package wordfreq
+
+import (
+	"bufio"
+	"io"
+	"sync"
+)
+
+// readBuffers and blockQueues hold the buffers of finished counts, so that
+// counting many inputs one after another, as the CLI does with its file
+// arguments, does not allocate them again for each input.
+var (
+	readBuffers sync.Pool // *readBuffer
+	blockQueues sync.Pool // chan []byte of free parallel blocks
+)
+
+// readBuffer is the buffered reader and read chunk of the sequential
+// reader loop.
+type readBuffer struct {
+	reader *bufio.Reader
+	chunk  []byte
+}
+
+// getReadBuffer returns a readBuffer of size bytes reading from r.
+func getReadBuffer(r io.Reader, size int) *readBuffer {
+	b, ok := readBuffers.Get().(*readBuffer)
+	if !ok || len(b.chunk) != size {
+		// NewReaderSize would return r itself if it were a large enough
+		// bufio.Reader, which must not end up in the pool.
+		b = &readBuffer{reader: bufio.NewReaderSize(nil, size), chunk: make([]byte, size)}
+	}
+	b.reader.Reset(r)
+	return b
+}
+
+// putReadBuffer returns b to the pool once its count is done.
+func putReadBuffer(b *readBuffer) {
+	b.reader.Reset(nil)
+	readBuffers.Put(b)
+}
+
+// getFreeBlocks returns a channel of n empty blocks of parallelBlockSize
+// bytes for splitBlocks to fill.
+func getFreeBlocks(n int) chan []byte {
+	free, ok := blockQueues.Get().(chan []byte)
+	if !ok || cap(free) != n {
+		free = make(chan []byte, n)
+	}
+	for len(free) < n {
+		free <- make([]byte, 0, parallelBlockSize)
+	}
+	return free
+}
+
+// putFreeBlocks returns free to the pool once every block is back in it or
+// dropped. Blocks grown past parallelBlockSize are dropped, so one huge
+// input does not keep its memory alive.
+func putFreeBlocks(free chan []byte) {
+	for range len(free) {
+		if b := <-free; cap(b) == parallelBlockSize {
+			free <- b[:0]
+		}
+	}
+	blockQueues.Put(free)
+}
@@ -0,0 +1,56 @@
+package wordfreq
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestReadBufferReused(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+	r := strings.NewReader("")
+	putReadBuffer(getReadBuffer(r, bufferSize))
+	allocs := testing.AllocsPerRun(100, func() {
+		putReadBuffer(getReadBuffer(r, bufferSize))
+	})
+	if allocs != 0 {
+		t.Errorf("getReadBuffer made %v allocations per input, want 0", allocs)
+	}
+
+	putFreeBlocks(getFreeBlocks(2 * testWorkers))
+	allocs = testing.AllocsPerRun(100, func() {
+		putFreeBlocks(getFreeBlocks(2 * testWorkers))
+	})
+	if allocs != 0 {
+		t.Errorf("getFreeBlocks made %v allocations per input, want 0", allocs)
+	}
+}
+
+func TestCountReusesReadBuffer(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+	const inputs = 50
+	opts := Options{MapSize: 16}
+	for _, workers := range []int{1, testWorkers} {
+		count := func() {
+			if _, _, err := CountParallel(strings.NewReader("one small file"), opts, workers); err != nil {
+				t.Fatal(err)
+			}
+		}
+		count()
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		for i := 0; i < inputs; i++ {
+			count()
+		}
+		runtime.ReadMemStats(&after)
+		// Each input still allocates its counter and tokenizer, but far
+		// less than a read buffer.
+		if perInput := (after.TotalAlloc - before.TotalAlloc) / inputs; perInput >= bufferSize {
+			t.Errorf("%d workers: %d bytes allocated per input, want less than the %d-byte read buffer", workers, perInput, bufferSize)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package wordfreq
+
+import (
+	"context"
+	"sync"
+)
+
+// TallyBytes counts the words in data, which is the complete input, for
+// example a memory-mapped file. No copying or carry-over between reads is
+// needed: data is cut into segments of about 1MB at safe split points (see
+// CountParallel) and the segments are scanned in place, by the given
+// number of goroutines. ctx is checked between segments; if it is done, the
+// Result for the segments finished so far is returned with ctx.Err().
+func TallyBytes(ctx context.Context, data []byte, opts Options, workers int) (*Result, error) {
+	data, limited := limitData(data, opts)
+	res, err := tallyBytes(ctx, data, opts, workers)
+	res.Limited = limited
+	return res, err
+}
+
+// tallyBytes is TallyBytes after Options.LimitBytes has been applied.
+func tallyBytes(ctx context.Context, data []byte, opts Options, workers int) (*Result, error) {
+	if workers <= 1 || opts.sequential() {
+		c := newCounter(opts)
+		s := newSegmentScanner(opts)
+		for len(data) > 0 {
+			if err := ctx.Err(); err != nil {
+				return c.result(), err
+			}
+			cut := nextSegment(data, opts)
+			s.scan(data[:cut], c)
+			data = data[cut:]
+		}
+		return c.result(), nil
+	}
+
+	segments := make(chan []byte, workers)
+	partials := newWorkerCounters(opts, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := newSegmentScanner(opts)
+			for segment := range segments {
+				s.scan(segment, partials[i])
+			}
+		}(i)
+	}
+
+	var err error
+	var offset int
+	log := opts.debugLogger()
+	for len(data) > 0 {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		cut := nextSegment(data, opts)
+		if log != nil {
+			log.Debug("segment", "offset", offset, "bytes", cut)
+		}
+		segments <- data[:cut]
+		data = data[cut:]
+		offset += cut
+	}
+	close(segments)
+	wg.Wait()
+
+	c := partials[0]
+	for _, p := range partials[1:] {
+		c.merge(p)
+	}
+	return c.result(), err
+}
+
+// segmentScanner scans whole segments with whichever loop opts selects.
+type segmentScanner struct {
+	fast    bool
+	tok     *tokenizer
+	wordBuf []byte
+}
+
+func newSegmentScanner(opts Options) *segmentScanner {
+	return &segmentScanner{
+		fast:    opts.fastPath(),
+		tok:     newTokenizer(opts),
+		wordBuf: make([]byte, 0, opts.wordLimit()),
+	}
+}
+
+func (s *segmentScanner) scan(segment []byte, c *counter) {
+	c.countText(segment)
+	if s.fast {
+		scanASCII(segment, true, c, s.wordBuf)
+	} else {
+		s.tok.scan(segment, true, c)
+	}
+}
+
+// nextSegment returns the length of the next segment of data: about
+// parallelBlockSize bytes, ending just after a safe split point, or all of
+// data if it has no split point in reach.
+func nextSegment(data []byte, opts Options) int {
+	if len(data) <= parallelBlockSize {
+		return len(data)
+	}
+	if cut := splitPoint(data[:parallelBlockSize], opts); cut > 0 {
+		return cut
+	}
+	for i := parallelBlockSize; i < len(data); i++ {
+		if canSplitAfter(data[i], opts) {
+			return i + 1
+		}
+	}
+	return len(data)
+}
@@ -0,0 +1,382 @@
+package wordfreq
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"regexp"
+	"unicode/utf8"
+)
+
+// counter accumulates word occurrences, applying the filters selected in
+// Options before a word is recorded.
+type counter struct {
+	counts store
+	words  int64
+	stop   map[string]struct{}
+	only   map[string]struct{} // nil unless there is an allow-list
+
+	// normalizer is Options.Normalizer.
+	normalizer Normalizer
+
+	// match and exclude are Options.Match and Options.Exclude.
+	match, exclude *regexp.Regexp
+
+	// sampling keeps only words hashing below sample; see Options.Sample.
+	sampling bool
+	sample   uint32
+
+	// Length limits, in bytes for the ASCII fast path. Words longer than
+	// maxLen are truncated to it when truncate is set and skipped
+	// otherwise. The tokenizer applies them itself, in characters with
+	// Options.Unicode.
+	minLen   int
+	maxLen   int
+	truncate bool
+
+	// caseSensitive tells scanASCII to copy words verbatim; class is the
+	// set of ASCII word characters it scans for.
+	caseSensitive bool
+	class         *byteClass
+
+	// maxUnique triggers pruning (0 = never); undercount accumulates the
+	// eviction thresholds, bounding how far any count may be too low.
+	maxUnique  int
+	pruned     bool
+	undercount int
+
+	// lengths is the word-length histogram, nil unless requested.
+	lengths []int64
+
+	// text enables the line, byte and character totals; runes counts
+	// characters as UTF-8 sequences rather than bytes.
+	text                bool
+	runes               bool
+	lines, bytes, chars int64
+
+	// ngram > 1 records grams instead of words, and cooccur > 0 pairs of
+	// words at most that far apart. gram holds the most recent words
+	// joined by spaces, starts the offset of each within it, and pair the
+	// key of the pair being recorded.
+	ngram   int
+	cooccur int
+	gram    []byte
+	starts  []int
+	pair    []byte
+
+	// spellings records the original spellings of each word, nil unless
+	// Options.Spelling is set; countSpellings tallies all of them rather
+	// than only the first.
+	spellings      map[string]*Spellings
+	countSpellings bool
+
+	// firstSeen records each new key in order, in seen; see
+	// Options.FirstSeen.
+	firstSeen bool
+	seen      []string
+
+	// emit, when set, receives each word instead of the store; see
+	// ScanWords.
+	emit func(word []byte)
+}
+
+func newCounter(opts Options) *counter {
+	c := &counter{
+		counts:     newStore(opts.Hash, opts.mapSize()),
+		normalizer: opts.Normalizer,
+		stop:       opts.StopWords,
+		only:       opts.OnlyWords,
+		match:      opts.Match,
+		exclude:    opts.Exclude,
+		minLen:     opts.MinLength,
+		maxLen:     opts.wordLimit(),
+		truncate:   opts.MaxLength == 0,
+
+		caseSensitive: opts.CaseSensitive,
+		class:         opts.class(),
+		maxUnique:     opts.MaxUnique,
+		ngram:         opts.NGram,
+		cooccur:       opts.Cooccur,
+		text:          opts.TextStats,
+		runes:         opts.Unicode,
+		firstSeen:     opts.FirstSeen,
+	}
+	c.sample, c.sampling = opts.sampleThreshold()
+	if opts.CountOnly {
+		// Words handed to emit are counted in the total and then dropped.
+		c.emit = func([]byte) {}
+	}
+	if opts.Spelling != SpellingNone && opts.NGram <= 1 && opts.Cooccur == 0 {
+		c.spellings = make(map[string]*Spellings)
+		c.countSpellings = opts.Spelling == SpellingCommon
+	}
+	if opts.Lengths {
+		c.lengths = make([]int64, opts.wordLimit()+1)
+	}
+	return c
+}
+
+// add records one occurrence of word, which was written as orig in the
+// input. Callers reuse the backing arrays of both for the next word; the
+// table copies new keys into its arena, so neither is retained.
+func (c *counter) add(word, orig []byte) {
+	if c.normalizer != nil {
+		if word = c.normalizer.Normalize(word); len(word) == 0 {
+			return
+		}
+	}
+	if c.stop != nil {
+		if _, ok := c.stop[string(word)]; ok {
+			return
+		}
+	}
+	if c.only != nil {
+		if _, ok := c.only[string(word)]; !ok {
+			return
+		}
+	}
+	if c.match != nil && !c.match.Match(word) {
+		return
+	}
+	if c.exclude != nil && c.exclude.Match(word) {
+		return
+	}
+	if c.sampling && fnv1aHash(word) >= c.sample {
+		return
+	}
+	if c.lengths != nil {
+		n := len(word)
+		if c.runes {
+			n = utf8.RuneCount(word)
+		}
+		if n >= len(c.lengths) {
+			// A Normalizer can lengthen a word past the limit.
+			c.lengths = append(c.lengths, make([]int64, n+1-len(c.lengths))...)
+		}
+		c.lengths[n]++
+	}
+	if c.cooccur > 0 {
+		c.addPairs(word)
+		return
+	}
+	if c.ngram > 1 {
+		c.addGram(word)
+		return
+	}
+	if c.spellings != nil {
+		c.spell(word, orig)
+	}
+	c.record(word)
+}
+
+// spell records that word was written as orig.
+func (c *counter) spell(word, orig []byte) {
+	s, ok := c.spellings[string(word)]
+	if !ok {
+		s = &Spellings{First: string(orig)}
+		c.spellings[string(word)] = s
+	}
+	if c.countSpellings {
+		if s.Counts == nil {
+			s.Counts = make(map[string]int, 1)
+		}
+		s.Counts[string(orig)]++
+	}
+}
+
+// addGram appends word to the current window and, once it holds ngram
+// words, records the gram and drops the oldest word.
+func (c *counter) addGram(word []byte) {
+	if len(c.starts) > 0 {
+		c.gram = append(c.gram, ' ')
+	}
+	c.starts = append(c.starts, len(c.gram))
+	c.gram = append(c.gram, word...)
+	if len(c.starts) < c.ngram {
+		return
+	}
+	c.record(c.gram)
+	c.dropOldest()
+}
+
+// addPairs records a pair of word with each different word in the
+// window of the cooccur words before it, then moves word into the
+// window, dropping the oldest word once it is full.
+func (c *counter) addPairs(word []byte) {
+	for i, start := range c.starts {
+		end := len(c.gram)
+		if i+1 < len(c.starts) {
+			end = c.starts[i+1] - 1
+		}
+		first, second := c.gram[start:end], word
+		switch bytes.Compare(first, second) {
+		case 0:
+			continue
+		case 1:
+			first, second = second, first
+		}
+		c.pair = append(append(append(c.pair[:0], first...), ' '), second...)
+		c.record(c.pair)
+	}
+
+	if len(c.starts) > 0 {
+		c.gram = append(c.gram, ' ')
+	}
+	c.starts = append(c.starts, len(c.gram))
+	c.gram = append(c.gram, word...)
+	if len(c.starts) > c.cooccur {
+		c.dropOldest()
+	}
+}
+
+// dropOldest removes the first of the two or more words in the window.
+func (c *counter) dropOldest() {
+	cut := c.starts[1]
+	c.gram = c.gram[:copy(c.gram, c.gram[cut:])]
+	c.starts = c.starts[:copy(c.starts, c.starts[1:])]
+	for i := range c.starts {
+		c.starts[i] -= cut
+	}
+}
+
+// countText adds data, a piece of raw input that no other call sees, to
+// the line, byte and character totals. Characters are counted as bytes
+// that do not continue a UTF-8 sequence, which gives the right total even
+// when a character is split between two pieces.
+func (c *counter) countText(data []byte) {
+	if !c.text {
+		return
+	}
+	c.lines += int64(bytes.Count(data, []byte{'\n'}))
+	c.bytes += int64(len(data))
+	if !c.runes {
+		c.chars += int64(len(data))
+		return
+	}
+	for _, b := range data {
+		if b&0xC0 != 0x80 {
+			c.chars++
+		}
+	}
+}
+
+// record counts one occurrence of an already filtered word or gram.
+func (c *counter) record(word []byte) {
+	c.words++
+	if c.emit != nil {
+		c.emit(word)
+		return
+	}
+	if c.firstSeen {
+		n := c.counts.len()
+		c.counts.add(word, 1)
+		if c.counts.len() > n {
+			c.seen = append(c.seen, string(word))
+		}
+	} else {
+		c.counts.add(word, 1)
+	}
+	if c.maxUnique > 0 && c.counts.len() > c.maxUnique {
+		c.prune()
+	}
+}
+
+// merge folds the counts of other into c.
+func (c *counter) merge(other *counter) {
+	c.counts.merge(other.counts)
+	c.words += other.words
+	c.pruned = c.pruned || other.pruned
+	c.undercount += other.undercount
+	c.lengths = addLengths(c.lengths, other.lengths)
+	c.lines += other.lines
+	c.bytes += other.bytes
+	c.chars += other.chars
+	c.spellings = mergeSpellings(c.spellings, other.spellings)
+	if c.maxUnique > 0 && c.counts.len() > c.maxUnique {
+		c.prune()
+	}
+}
+
+// prune evicts the lowest-count words, leaving at most half of maxUnique.
+// A word evicted and seen again restarts from zero, so each prune can make
+// a surviving count low by at most the eviction threshold.
+func (c *counter) prune() {
+	var evicted func(word string)
+	if c.spellings != nil {
+		evicted = func(word string) { delete(c.spellings, word) }
+	}
+	c.undercount += c.counts.pruneTo(max(c.maxUnique/2, 1), evicted)
+	c.pruned = true
+}
+
+func (c *counter) result() *Result {
+	counts := c.counts.toMap()
+	if c.pruned && c.spellings != nil {
+		// Workers sharing a HashSharded store only drop the words their
+		// own prunes evicted, so the spellings merged from them can name
+		// words a prune by another worker removed.
+		for word := range c.spellings {
+			if _, ok := counts[word]; !ok {
+				delete(c.spellings, word)
+			}
+		}
+	}
+	return &Result{
+		Counts:        counts,
+		TotalWords:    c.words,
+		Pruned:        c.pruned,
+		MaxUndercount: c.undercount,
+		Lengths:       c.lengths,
+		Lines:         c.lines,
+		Bytes:         c.bytes,
+		Chars:         c.chars,
+		Spellings:     c.spellings,
+		FirstSeen:     firstSeen(c.seen, counts, c.pruned),
+	}
+}
+
+// firstSeen returns seen, the keys in the order they were added to the
+// store, as Result.FirstSeen for counts. After pruning it drops the words
+// evicted for good, and the second entries of those counted again.
+func firstSeen(seen []string, counts map[string]int, pruned bool) []string {
+	if !pruned {
+		return seen
+	}
+	kept := seen[:0]
+	listed := make(map[string]struct{}, len(counts))
+	for _, word := range seen {
+		if _, ok := counts[word]; !ok {
+			continue
+		}
+		if _, ok := listed[word]; ok {
+			continue
+		}
+		listed[word] = struct{}{}
+		kept = append(kept, word)
+	}
+	return kept
+}
+
+// ReadWordSet reads a newline-delimited word list, such as a stop-word
+// file, and returns the set of its words normalized under opts so that
+// they match the keys Count produces. Blank lines and lines without a word
+// are ignored.
+func ReadWordSet(r io.Reader, opts Options) (map[string]struct{}, error) {
+	set := make(map[string]struct{})
+	buf := make([]byte, 0, opts.wordLimit())
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		word, _, ok := ExtractWord(scanner.Bytes(), 0, buf, opts)
+		if ok && opts.Normalizer != nil {
+			word = opts.Normalizer.Normalize(word)
+			ok = len(word) > 0
+		}
+		if ok {
+			set[string(word)] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
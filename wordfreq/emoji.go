@@ -0,0 +1,118 @@
+package wordfreq
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// pictographic approximates the Unicode Extended_Pictographic property,
+// the characters an emoji cluster can be built on, leaving out the skin
+// tone modifiers, which only follow one.
+var pictographic = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{0x00A9, 0x00A9, 1}, {0x00AE, 0x00AE, 1}, {0x203C, 0x203C, 1}, {0x2049, 0x2049, 1},
+		{0x2122, 0x2122, 1}, {0x2139, 0x2139, 1}, {0x2194, 0x2199, 1}, {0x21A9, 0x21AA, 1},
+		{0x231A, 0x231B, 1}, {0x2328, 0x2328, 1}, {0x23CF, 0x23CF, 1}, {0x23E9, 0x23F3, 1},
+		{0x23F8, 0x23FA, 1}, {0x24C2, 0x24C2, 1}, {0x25AA, 0x25AB, 1}, {0x25B6, 0x25B6, 1},
+		{0x25C0, 0x25C0, 1}, {0x25FB, 0x25FE, 1}, {0x2600, 0x27BF, 1}, {0x2934, 0x2935, 1},
+		{0x2B05, 0x2B07, 1}, {0x2B1B, 0x2B1C, 1}, {0x2B50, 0x2B50, 1}, {0x2B55, 0x2B55, 1},
+		{0x3030, 0x3030, 1}, {0x303D, 0x303D, 1}, {0x3297, 0x3297, 1}, {0x3299, 0x3299, 1},
+	},
+	R32: []unicode.Range32{
+		{0x1F000, 0x1F0FF, 1}, {0x1F10D, 0x1F10F, 1}, {0x1F12F, 0x1F12F, 1}, {0x1F16C, 0x1F171, 1},
+		{0x1F17E, 0x1F17F, 1}, {0x1F18E, 0x1F18E, 1}, {0x1F191, 0x1F19A, 1}, {0x1F1AD, 0x1F1E5, 1},
+		{0x1F201, 0x1F20F, 1}, {0x1F21A, 0x1F21A, 1}, {0x1F22F, 0x1F22F, 1}, {0x1F232, 0x1F23A, 1},
+		{0x1F23C, 0x1F23F, 1}, {0x1F249, 0x1F3FA, 1}, {0x1F400, 0x1F53D, 1}, {0x1F546, 0x1F64F, 1},
+		{0x1F680, 0x1F6FF, 1}, {0x1F774, 0x1F77F, 1}, {0x1F7D5, 0x1F7FF, 1}, {0x1F80C, 0x1F80F, 1},
+		{0x1F848, 0x1F84F, 1}, {0x1F85A, 0x1F85F, 1}, {0x1F888, 0x1F88F, 1}, {0x1F8AE, 0x1F8FF, 1},
+		{0x1F90C, 0x1F93A, 1}, {0x1F93C, 0x1F945, 1}, {0x1F947, 0x1FAFF, 1}, {0x1FC00, 0x1FFFD, 1},
+	},
+}
+
+// The characters that extend an emoji cluster, and the regional
+// indicators, pairs of which spell a flag.
+const (
+	zwj               = '\u200D'
+	emojiPresentation = '\uFE0F'
+	skinToneFirst     = 0x1F3FB
+	skinToneLast      = 0x1F3FF
+	tagFirst          = 0xE0020
+	tagLast           = 0xE007F
+	regionalFirst     = 0x1F1E6
+	regionalLast      = 0x1F1FF
+)
+
+func isRegional(r rune) bool { return r >= regionalFirst && r <= regionalLast }
+
+// emoji finds the end of the emoji cluster at data[pos:], for
+// Options.Emoji. A cluster is a flag (two regional indicators) or a
+// pictographic character, optionally followed by U+FE0F, a skin tone and
+// tag characters, then any number of further pictographs, with the same
+// suffixes, each joined by a zero-width joiner. ok is false when data does
+// not start with one, as for a lone regional indicator; more is as for
+// tokenizer.next.
+func emoji(data []byte, pos int, atEOF bool) (end int, ok, more bool) {
+	peek := func(i int) (rune, int, bool) {
+		if i >= len(data) || !utf8.FullRune(data[i:]) {
+			return utf8.RuneError, 0, !atEOF
+		}
+		r, size := utf8.DecodeRune(data[i:])
+		return r, size, false
+	}
+	// suffix skips the optional characters that may follow a pictograph.
+	suffix := func(i int) (int, bool) {
+		r, size, more := peek(i)
+		if r == emojiPresentation {
+			i += size
+			r, size, more = peek(i)
+		}
+		if r >= skinToneFirst && r <= skinToneLast {
+			i += size
+			r, size, more = peek(i)
+		}
+		for r >= tagFirst && r <= tagLast {
+			i += size
+			r, size, more = peek(i)
+		}
+		return i, more
+	}
+
+	r, size, _ := peek(pos)
+	end = pos + size
+	switch {
+	case isRegional(r):
+		r, size, more = peek(end)
+		if more || !isRegional(r) {
+			return pos, false, more
+		}
+		end += size
+	case unicode.Is(pictographic, r):
+		if end, more = suffix(end); more {
+			return pos, false, true
+		}
+	default:
+		return pos, false, false
+	}
+
+	for {
+		r, size, more = peek(end)
+		if more {
+			return pos, false, true
+		}
+		if r != zwj {
+			return end, true, false
+		}
+		next, nextSize, more := peek(end + size)
+		if more {
+			return pos, false, true
+		}
+		if !unicode.Is(pictographic, next) {
+			return end, true, false
+		}
+		joined, more := suffix(end + size + nextSize)
+		if more {
+			return pos, false, true
+		}
+		end = joined
+	}
+}
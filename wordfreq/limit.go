@@ -0,0 +1,81 @@
+package wordfreq
+
+import (
+	"io"
+	"unicode/utf8"
+)
+
+// limitReader implements Options.LimitBytes for a stream: it passes on the
+// first n bytes of r and then the bytes up to the end of the word there,
+// and after that reports io.EOF.
+type limitReader struct {
+	r     io.Reader
+	opts  Options
+	left  int64 // bytes still to pass on before the limit
+	extra int   // bytes passed on since the limit, looking for the word's end
+	done  bool  // the word at the limit has ended
+}
+
+// limitInput returns r cut short by opts.LimitBytes, and the limitReader
+// doing it, or r itself and nil when there is no limit.
+func limitInput(r io.Reader, opts Options) (io.Reader, *limitReader) {
+	if opts.LimitBytes <= 0 {
+		return r, nil
+	}
+	l := &limitReader{r: r, opts: opts, left: opts.LimitBytes}
+	return l, l
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.done {
+		return 0, io.EOF
+	}
+	n, err := l.r.Read(p)
+	if int64(n) < l.left {
+		l.left -= int64(n)
+		return n, err
+	}
+	from := int(l.left)
+	l.left = 0
+	if end, ok := wordEnd(p[:n], from, l.extra, l.opts); ok {
+		l.done = true
+		return end, nil
+	}
+	l.extra += n - from
+	return n, err
+}
+
+// reached reports whether the input went on past the limit, so that only
+// a prefix of it was read. It is false for a nil l.
+func (l *limitReader) reached() bool {
+	return l != nil && l.done
+}
+
+// limitData applies opts.LimitBytes to data, the complete input, and
+// reports whether it cut anything off.
+func limitData(data []byte, opts Options) ([]byte, bool) {
+	if opts.LimitBytes <= 0 || int64(len(data)) <= opts.LimitBytes {
+		return data, false
+	}
+	end, ok := wordEnd(data, int(opts.LimitBytes), 0, opts)
+	if !ok || end == len(data) {
+		return data, false
+	}
+	return data[:end], true
+}
+
+// wordEnd returns the length of data up to and including the first byte
+// at or after from that cannot belong to a word, so a cut there keeps the
+// word running at from whole. A word longer than any that is counted in
+// full ends once extra, the bytes of it seen before data, and those in
+// data reach a length it is cut or skipped at anyway. ok is false if data
+// ends first.
+func wordEnd(data []byte, from, extra int, opts Options) (end int, ok bool) {
+	longest := (opts.wordLimit() + 1) * utf8.UTFMax
+	for i := from; i < len(data); i++ {
+		if canSplitAfter(data[i], opts) || extra+i-from >= longest {
+			return i + 1, true
+		}
+	}
+	return 0, false
+}
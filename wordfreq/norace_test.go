@@ -0,0 +1,5 @@
+//go:build !race
+
+package wordfreq
+
+const raceEnabled = false
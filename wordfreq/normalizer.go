@@ -0,0 +1,60 @@
+package wordfreq
+
+import "bytes"
+
+// Normalizer rewrites each word before it is counted, for normalization
+// that Options has no setting for, such as mapping brand-name variants to
+// one canonical form. It sees the word as recognized, case-folded and cut
+// to length under Options, and what it returns is what the filters see,
+// the store counts and Result.Counts holds; StopWords and OnlyWords keys
+// must be in that form too, as ReadWordSet makes them.
+type Normalizer interface {
+	// Normalize returns the form of word to count, or an empty slice to
+	// skip it as if it were a stop word. It may rewrite word in place and
+	// return it, or return other memory; the result is not retained past
+	// the next call. With more than one worker it is called from several
+	// goroutines at once.
+	Normalize(word []byte) []byte
+}
+
+// NormalizerFunc adapts an ordinary function to the Normalizer interface.
+type NormalizerFunc func(word []byte) []byte
+
+// Normalize returns f(word).
+func (f NormalizerFunc) Normalize(word []byte) []byte { return f(word) }
+
+// Normalizers chains normalizers, applying each to the result of the one
+// before. A word one of them skips is not passed to the rest.
+type Normalizers []Normalizer
+
+// Normalize applies every normalizer in n in order.
+func (n Normalizers) Normalize(word []byte) []byte {
+	for _, norm := range n {
+		if word = norm.Normalize(word); len(word) == 0 {
+			break
+		}
+	}
+	return word
+}
+
+// The built-in normalizers. Counting already lowercases words unless
+// Options.CaseSensitive is set, so Lowercase is for chains that need the
+// original case first; PorterStem and Soundex merge words that share a
+// stem or a sound, where the command-line tool groups them after counting
+// instead, so it can list the variants.
+var (
+	// Lowercase maps every letter to lower case, ASCII or not.
+	Lowercase Normalizer = NormalizerFunc(bytes.ToLower)
+
+	// PorterStem replaces a word of lowercase ASCII letters by its Porter
+	// stem; see Stem.
+	PorterStem Normalizer = NormalizerFunc(func(word []byte) []byte {
+		return append(word[:0:len(word)], Stem(string(word))...)
+	})
+
+	// Soundex replaces a word by its American Soundex code; see
+	// SoundexCode.
+	Soundex Normalizer = NormalizerFunc(func(word []byte) []byte {
+		return append(word[:0:len(word)], SoundexCode(string(word))...)
+	})
+)
@@ -0,0 +1,188 @@
+package wordfreq
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// parallelBlockSize is the amount of input handed to a worker at a time.
+const parallelBlockSize = 1024 * 1024 // 1MB
+
+// CountParallel is like Count but spreads the work over the given number of
+// goroutines. Input is read sequentially and cut into blocks that end on a
+// byte which can never be part of a word, so no word straddles two blocks
+// and each is counted exactly once. Every worker counts into its own map,
+// and the maps are merged when the input is exhausted, unless opts selects
+// HashSharded. With workers <= 1 it is
+// equivalent to Count.
+func CountParallel(r io.Reader, opts Options, workers int) (map[string]int, int64, error) {
+	res, err := Tally(r, opts, workers)
+	if res == nil {
+		return nil, 0, err
+	}
+	return res.Counts, res.TotalWords, err
+}
+
+// countParallel is the worker pool behind CountParallel.
+func countParallel(ctx context.Context, r io.Reader, opts Options, workers int) (*counter, error) {
+	blocks := make(chan []byte, workers)
+	free := getFreeBlocks(2 * workers)
+
+	partials := newWorkerCounters(opts, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := partials[i]
+			tok := newTokenizer(opts)
+			wordBuf := make([]byte, 0, opts.wordLimit())
+			for block := range blocks {
+				c.countText(block)
+				if opts.fastPath() {
+					scanASCII(block, true, c, wordBuf)
+				} else {
+					tok.scan(block, true, c)
+				}
+				free <- block[:0]
+			}
+		}(i)
+	}
+
+	err := splitBlocks(ctx, r, opts, blocks, free)
+	close(blocks)
+	wg.Wait()
+	putFreeBlocks(free)
+
+	c := partials[0]
+	for _, p := range partials[1:] {
+		c.merge(p)
+	}
+	return c, err
+}
+
+// countRecords is count for Options.RecordSep: r is cut into blocks of
+// whole records by splitBlocks, as for CountParallel, and each block is
+// scanned to its end in order, so nothing is carried between them.
+func countRecords(ctx context.Context, c *counter, r io.Reader, opts Options) (*counter, error) {
+	blocks := make(chan []byte, 1)
+	free := getFreeBlocks(2)
+	var err error
+	go func() {
+		err = splitBlocks(ctx, r, opts, blocks, free)
+		close(blocks)
+	}()
+	s := newSegmentScanner(opts)
+	for block := range blocks {
+		s.scan(block, c)
+		free <- block[:0]
+	}
+	putFreeBlocks(free)
+	return c, err
+}
+
+// splitBlocks reads r into buffers taken from free and sends them on
+// blocks, each cut just after the last safe split point. The bytes after
+// that point are carried to the start of the next block. A block with no
+// split point at all is grown until one is found or the input ends.
+// Reading stops early, returning ctx.Err(), once ctx is done.
+func splitBlocks(ctx context.Context, r io.Reader, opts Options, blocks chan<- []byte, free <-chan []byte) error {
+	var carry []byte
+	var offset int64
+	log := opts.debugLogger()
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		buf := append(<-free, carry...)
+		for {
+			n, err := fill(r, buf[len(buf):cap(buf)])
+			buf = buf[:len(buf)+n]
+			if err == io.EOF {
+				if len(buf) > 0 {
+					if log != nil {
+						log.Debug("final block", "offset", offset, "bytes", len(buf))
+					}
+					blocks <- buf
+				}
+				return nil
+			}
+			if err != nil {
+				// Whole words read before the failure are still counted.
+				if cut := splitPoint(buf, opts); cut > 0 {
+					blocks <- buf[:cut]
+				}
+				return err
+			}
+
+			if cut := splitPoint(buf, opts); cut > 0 {
+				carry = append(carry[:0], buf[cut:]...)
+				if log != nil {
+					log.Debug("block", "offset", offset, "bytes", cut, "carry", len(carry))
+				}
+				offset += int64(cut)
+				blocks <- buf[:cut]
+				break
+			}
+			if log != nil {
+				log.Debug("no split point, growing block", "offset", offset, "bytes", len(buf))
+			}
+			buf = append(buf, make([]byte, len(buf))...)[:len(buf)]
+		}
+	}
+}
+
+// fill reads into b until it is full or reading fails. It is io.ReadFull
+// without the translation of io.EOF, so the end of the input stays
+// distinct from a reader failing with io.ErrUnexpectedEOF, as gzip does
+// on a truncated stream.
+func fill(r io.Reader, b []byte) (int, error) {
+	n := 0
+	for n < len(b) {
+		m, err := r.Read(b[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// splitPoint returns the offset just past the last byte of data that can
+// never belong to a word under opts, or 0 if there is none. In the ASCII
+// fast path every non-word byte qualifies; the general tokenizer joins some
+// punctuation and multibyte characters into words, so only ASCII
+// whitespace (that is not itself a word character) is safe there. With
+// Options.RecordSep only the separator is.
+func splitPoint(data []byte, opts Options) int {
+	if opts.RecordSep != 0 {
+		return bytes.LastIndexByte(data, opts.RecordSep) + 1
+	}
+	for i := len(data) - 1; i >= 0; i-- {
+		if canSplitAfter(data[i], opts) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// canSplitAfter reports whether b can never belong to a word under opts.
+func canSplitAfter(b byte, opts Options) bool {
+	if opts.RecordSep != 0 {
+		return b == opts.RecordSep
+	}
+	if opts.Delimiter != 0 {
+		return b == opts.Delimiter
+	}
+	class := opts.class()
+	if opts.fastPath() {
+		return !class[b]
+	}
+	return isSpace(b) && !class[b]
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\n' || b == '\t' || b == '\r' || b == '\v' || b == '\f'
+}
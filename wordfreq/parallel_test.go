@@ -0,0 +1,131 @@
+package wordfreq
+
+import (
+	"bytes"
+	"context"
+	"maps"
+	"testing"
+	"testing/iotest"
+)
+
+// testWorkers is the number of goroutines the parallel paths are run with.
+const testWorkers = 4
+
+// checkParallel counts data sequentially and then with CountParallel and
+// TallyBytes, and fails t if the parallel results differ.
+func checkParallel(t *testing.T, data []byte, opts Options) {
+	t.Helper()
+	want, wantTotal, err := Count(bytes.NewReader(data), opts)
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	got, total, err := CountParallel(bytes.NewReader(data), opts, testWorkers)
+	if err != nil {
+		t.Fatalf("CountParallel: %v", err)
+	}
+	if total != wantTotal || !maps.Equal(got, want) {
+		t.Errorf("CountParallel: %d words, %d distinct; sequential: %d words, %d distinct", total, len(got), wantTotal, len(want))
+	}
+	res, err := TallyBytes(context.Background(), data, opts, testWorkers)
+	if err != nil {
+		t.Fatalf("TallyBytes: %v", err)
+	}
+	if res.TotalWords != wantTotal || !maps.Equal(res.Counts, want) {
+		t.Errorf("TallyBytes: %d words, %d distinct; sequential: %d words, %d distinct", res.TotalWords, len(res.Counts), wantTotal, len(want))
+	}
+}
+
+// boundaryText returns filler of "ab " words up to offset, then text and a
+// closing word, so text starts offset bytes into the input.
+func boundaryText(offset int, text string) []byte {
+	data := bytes.Repeat([]byte("ab "), offset/3+1)[:offset]
+	if offset > 0 {
+		data[offset-1] = ' '
+	}
+	data = append(data, text...)
+	return append(data, " end\n"...)
+}
+
+func TestParallelBlockBoundary(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		opts Options
+	}{
+		{"ascii", "hello world", Options{}},
+		{"contraction", "don't stop", Options{Contractions: true}},
+		{"hyphen", "well-known fact", Options{Hyphens: true}},
+		{"digits", "route 66 north", Options{Digits: true}},
+		{"unicode", "naïve café", Options{Unicode: true}},
+		{"case-sensitive", "Hello hello", Options{CaseSensitive: true}},
+		{"ngram", "one two three", Options{NGram: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for offset := parallelBlockSize - len(tt.text) - 2; offset <= parallelBlockSize+2; offset++ {
+				checkParallel(t, boundaryText(offset, tt.text), tt.opts)
+				if t.Failed() {
+					t.Fatalf("text at offset %d", offset)
+				}
+			}
+		})
+	}
+}
+
+func TestParallelLongWordAcrossBoundary(t *testing.T) {
+	long := string(bytes.Repeat([]byte("a"), 10000))
+	for _, opts := range []Options{{}, {Unicode: true}, {MaxLength: 20}} {
+		// The word is truncated, or skipped under MaxLength, wherever the
+		// block boundary falls in it.
+		want := map[string]int{"x": 1, "y": 1, "end": 1}
+		if opts.MaxLength == 0 {
+			want[long[:MaxWordLength]] = 1
+		}
+		for _, offset := range []int{parallelBlockSize - 9999, parallelBlockSize - 5000, parallelBlockSize - 1, parallelBlockSize} {
+			data := boundaryText(offset, "x "+long+" y")
+			checkParallel(t, data, opts)
+			counts, _, err := CountParallel(bytes.NewReader(data), opts, testWorkers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			maps.DeleteFunc(counts, func(word string, _ int) bool { return word == "ab" || word == "a" })
+			if !maps.Equal(counts, want) {
+				t.Errorf("%+v, word at offset %d: got %d distinct words besides the filler, want %d", opts, offset, len(counts), len(want))
+			}
+		}
+	}
+}
+
+func TestParallelMultibyteSplit(t *testing.T) {
+	opts := Options{Unicode: true}
+	for _, word := range []string{"zé", "z中", "z𐐨"} { // 2-, 3- and 4-byte runes
+		text := word + " " + word + word
+
+		// Read a byte at a time, the sequential reader splits every rune
+		// at each of its offsets.
+		counts, _, err := Count(iotest.OneByteReader(bytes.NewReader([]byte(text))), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]int{word: 1, word + word: 1}; !maps.Equal(counts, want) {
+			t.Errorf("%q read a byte at a time: got %v, want %v", word, counts, want)
+		}
+
+		// The offsets place the parallel block boundary at each byte of
+		// text.
+		for offset := parallelBlockSize - len(text) - 1; offset <= parallelBlockSize+1; offset++ {
+			data := boundaryText(offset, text)
+			want, wantTotal, err := Count(bytes.NewReader(data), opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, total, err := CountParallel(iotest.OneByteReader(bytes.NewReader(data)), opts, testWorkers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != wantTotal || !maps.Equal(got, want) || got[word] != 1 || got[word+word] != 1 {
+				t.Errorf("%q at offset %d: CountParallel of one-byte reads differs from Count", word, offset)
+			}
+		}
+	}
+}
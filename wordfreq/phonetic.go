@@ -0,0 +1,44 @@
+package wordfreq
+
+// soundexCodes maps each lowercase ASCII letter to its Soundex digit. Vowels
+// and y are '0', which separates repeated digits; h and w are left as 0
+// bytes, which do not.
+var soundexCodes = [26]byte{
+	'0', '1', '2', '3', '0', '1', '2', 0, '0', '2', '2', '4', '5',
+	'5', '0', '1', '2', '6', '2', '3', '0', '1', 0, '2', '0', '2',
+}
+
+// SoundexCode returns the American Soundex code of word, such as "s530" for
+// both "smith" and "smyth", ignoring case and any byte that is not an
+// ASCII letter. A word with no ASCII letters is its own code, so it is
+// never grouped with another.
+func SoundexCode(word string) string {
+	code := make([]byte, 0, 4)
+	var last byte
+	for i := 0; i < len(word) && len(code) < 4; i++ {
+		b := word[i] | 0x20
+		if b < 'a' || b > 'z' {
+			continue
+		}
+		digit := soundexCodes[b-'a']
+		if len(code) == 0 {
+			code = append(code, b)
+			last = digit
+			continue
+		}
+		if digit == 0 {
+			continue
+		}
+		if digit != '0' && digit != last {
+			code = append(code, digit)
+		}
+		last = digit
+	}
+	if len(code) == 0 {
+		return word
+	}
+	for len(code) < 4 {
+		code = append(code, '0')
+	}
+	return string(code)
+}
@@ -0,0 +1,273 @@
+package wordfreq
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+// fuzzOptions builds the Options a FuzzCount case runs under from its
+// flag bits and lengths.
+func fuzzOptions(flags uint16, minLen, maxLen uint8) Options {
+	opts := Options{
+		Unicode:       flags&(1<<0) != 0,
+		Contractions:  flags&(1<<1) != 0,
+		Hyphens:       flags&(1<<2) != 0,
+		Digits:        flags&(1<<3) != 0,
+		CaseSensitive: flags&(1<<4) != 0,
+		Emoji:         flags&(1<<5) != 0,
+		MinLength:     int(minLen % 4),
+		MaxLength:     int(maxLen % 8),
+	}
+	if flags&(1<<6) != 0 {
+		opts.NGram = 2
+	}
+	if flags&(1<<7) != 0 {
+		opts.Delimiter = ','
+	}
+	if flags&(1<<8) != 0 {
+		opts.RecordSep = '\n'
+	}
+	return opts
+}
+
+// FuzzCount checks Count, CountParallel and TallyBytes against
+// countReference for random input under random Options.
+func FuzzCount(f *testing.F) {
+	seeds := []string{
+		"",
+		"The quick brown fox. The lazy dog!",
+		"don't well-being it's co-op ’quoted’ ‐",
+		"Ünïcödé straße ΣΊΣΥΦΟΣ 本語 𐐨𐐩",
+		"flags 🇫🇷 and 👍🏽 and 👩‍💻, emoji",
+		"a,b,,c\r\nd,E\n",
+		"error404 2023 x1y2",
+		"\xef\xbb\xbfbom \xff\xfe invalid \xc3",
+		strings.Repeat("x", 250) + " tail",
+	}
+	for i, seed := range seeds {
+		f.Add([]byte(seed), uint16(i*37), uint8(i), uint8(i*3), uint8(i%4+1))
+	}
+	f.Fuzz(func(t *testing.T, data []byte, flags uint16, minLen, maxLen, workers uint8) {
+		opts := fuzzOptions(flags, minLen, maxLen)
+		want, err := countReference(bytes.NewReader(data), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n := int(workers%4) + 1
+
+		got, _, err := Count(bytes.NewReader(data), opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(got, want) {
+			t.Errorf("Count(%q, %+v) = %v, want %v", data, opts, got, want)
+		}
+		got, _, err = CountParallel(bytes.NewReader(data), opts, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(got, want) {
+			t.Errorf("CountParallel(%q, %+v, %d) = %v, want %v", data, opts, n, got, want)
+		}
+		res, err := TallyBytes(context.Background(), data, opts, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !maps.Equal(res.Counts, want) {
+			t.Errorf("TallyBytes(%q, %+v, %d) = %v, want %v", data, opts, n, res.Counts, want)
+		}
+	})
+}
+
+// countReference is a deliberately simple counter meant for checking Count
+// against: it reads all of r into memory and finds words with a regular
+// expression built from opts, or by splitting on Options.Delimiter. It
+// supports every option except MaxUnique, whose results are approximate by
+// design, and is far slower than Count.
+func countReference(r io.Reader, opts Options) (map[string]int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data, _ = limitData(data, opts)
+
+	var matches []string
+	if opts.Delimiter != 0 {
+		for _, token := range strings.Split(string(data), string(opts.Delimiter)) {
+			if opts.Delimiter == '\n' {
+				token = strings.TrimSuffix(token, "\r")
+			}
+			if token == "" {
+				continue
+			}
+			if !opts.CaseSensitive {
+				token = foldToken(token, opts.Unicode)
+			}
+			matches = append(matches, token)
+		}
+	} else {
+		matches = findWords(data, opts)
+	}
+
+	limit := opts.wordLimit()
+	threshold, sampling := opts.sampleThreshold()
+	var words []string
+	length := func(word string) int { return len(word) }
+	if opts.Unicode {
+		length = utf8.RuneCountInString
+	}
+	for _, word := range matches {
+		if length(word) > limit {
+			if opts.MaxLength > 0 {
+				continue
+			}
+			word = truncateRunes(word, limit, opts.Unicode)
+		}
+		if length(word) < opts.MinLength {
+			continue
+		}
+		if opts.Normalizer != nil {
+			if word = string(opts.Normalizer.Normalize([]byte(word))); word == "" {
+				continue
+			}
+		}
+		if _, ok := opts.StopWords[word]; ok {
+			continue
+		}
+		if _, ok := opts.OnlyWords[word]; opts.OnlyWords != nil && !ok {
+			continue
+		}
+		if opts.Match != nil && !opts.Match.MatchString(word) {
+			continue
+		}
+		if opts.Exclude != nil && opts.Exclude.MatchString(word) {
+			continue
+		}
+		if sampling && fnv1aHash([]byte(word)) >= threshold {
+			continue
+		}
+		words = append(words, word)
+	}
+
+	counts := make(map[string]int)
+	if opts.Cooccur > 0 {
+		for i, first := range words {
+			for _, second := range words[i+1 : min(i+1+opts.Cooccur, len(words))] {
+				if first == second {
+					continue
+				}
+				if first > second {
+					counts[second+" "+first]++
+				} else {
+					counts[first+" "+second]++
+				}
+			}
+		}
+		return counts, nil
+	}
+	n := max(opts.NGram, 1)
+	for i := 0; i+n <= len(words); i++ {
+		counts[strings.Join(words[i:i+n], " ")]++
+	}
+	return counts, nil
+}
+
+// findWords returns the words of data, normalized, with a regular
+// expression built from opts.
+func findWords(data []byte, opts Options) []string {
+	// One word character: an ASCII byte from the class, or with Unicode a
+	// non-ASCII letter (anything not a non-letter and not ASCII).
+	var ascii strings.Builder
+	for b, ok := range opts.class()[:utf8.RuneSelf] {
+		if ok {
+			fmt.Fprintf(&ascii, `\x{%x}`, b)
+		}
+	}
+	letter := `[` + ascii.String() + `]`
+	if opts.Unicode {
+		letter = `(?:` + letter + `|[^\P{L}\x00-\x7F])`
+	}
+
+	joiners := ""
+	if opts.Contractions {
+		joiners += `'’`
+	}
+	if opts.Hyphens {
+		joiners += `\-‐`
+	}
+	pattern := letter + `+`
+	if joiners != "" {
+		pattern += `(?:[` + joiners + `]` + letter + `+)*`
+	}
+	if opts.Emoji && opts.Unicode {
+		pattern = emojiPattern() + `|` + pattern
+	}
+	re := regexp.MustCompile(pattern)
+
+	var words []string
+	for _, match := range re.FindAll(data, -1) {
+		word := strings.NewReplacer("’", "'", "‐", "-").Replace(string(match))
+		if !opts.CaseSensitive {
+			word = strings.ToLower(word)
+		}
+		words = append(words, word)
+	}
+	return words
+}
+
+// foldToken lowercases the ASCII letters of a delimited token and, when
+// all is set, its other valid characters. Invalid bytes are kept.
+func foldToken(token string, all bool) string {
+	var b strings.Builder
+	for i, r := range token {
+		switch {
+		case r == utf8.RuneError && !strings.HasPrefix(token[i:], "\uFFFD"):
+			b.WriteByte(token[i])
+		case r < utf8.RuneSelf || all:
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// truncateRunes cuts s to at most limit bytes without splitting a
+// character, or with chars to its first limit characters.
+func truncateRunes(s string, limit int, chars bool) string {
+	if chars {
+		for i := range s {
+			if limit == 0 {
+				return s[:i]
+			}
+			limit--
+		}
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
+
+// emojiPattern is the regular expression form of emoji, for
+// countReference.
+func emojiPattern() string {
+	class := ""
+	for _, r := range pictographic.R16 {
+		class += fmt.Sprintf(`\x{%X}-\x{%X}`, r.Lo, r.Hi)
+	}
+	for _, r := range pictographic.R32 {
+		class += fmt.Sprintf(`\x{%X}-\x{%X}`, r.Lo, r.Hi)
+	}
+	base := `[` + class + `]\x{FE0F}?[\x{1F3FB}-\x{1F3FF}]?[\x{E0020}-\x{E007F}]*`
+	return `(?:[\x{1F1E6}-\x{1F1FF}]{2}|` + base + `)(?:\x{200D}` + base + `)*`
+}
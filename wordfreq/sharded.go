@@ -0,0 +1,116 @@
+package wordfreq
+
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// shardCount is the number of independently locked maps in a shardedStore.
+const shardCount = 256
+
+// cacheLineSize is the size of a CPU cache line on common hardware.
+const cacheLineSize = 64
+
+// shardedStore is the HashSharded store: maps guarded by their own mutex,
+// chosen by the low bits of a word's FNV-1a hash. The parallel workers all
+// count into one shardedStore, so there are no per-worker maps to merge
+// once the input is exhausted.
+type shardedStore struct {
+	shards [shardCount]shardMap
+	unique atomic.Int64
+}
+
+// shardMap is one map of a shardedStore with its lock, padded to a whole
+// cache line so that neighbouring locks never share one.
+type shardMap struct {
+	mu sync.Mutex
+	m  map[string]*int // a pointer, so counting a known word never allocates its key
+	_  [cacheLineSize - unsafe.Sizeof(sync.Mutex{}) - unsafe.Sizeof(map[string]*int(nil))]byte
+}
+
+func newShardedStore(size int) *shardedStore {
+	s := &shardedStore{}
+	for i := range s.shards {
+		s.shards[i].m = make(map[string]*int, size/shardCount)
+	}
+	return s
+}
+
+func (s *shardedStore) add(word []byte, n int) {
+	shard := &s.shards[fnv1aHash(word)%shardCount]
+	shard.mu.Lock()
+	if count, ok := shard.m[string(word)]; ok {
+		*count += n
+		shard.mu.Unlock()
+		return
+	}
+	count := new(int)
+	*count = n
+	shard.m[string(word)] = count
+	shard.mu.Unlock()
+	s.unique.Add(1)
+}
+
+func (s *shardedStore) len() int { return int(s.unique.Load()) }
+
+// merge and toMap take each shard's lock while they read it, as add does,
+// so they are safe while other workers are still counting.
+func (s *shardedStore) merge(other store) {
+	o := other.(*shardedStore)
+	if o == s {
+		return
+	}
+	for i := range o.shards {
+		shard := &o.shards[i]
+		shard.mu.Lock()
+		for word, n := range shard.m {
+			s.add([]byte(word), *n)
+		}
+		shard.mu.Unlock()
+	}
+}
+
+func (s *shardedStore) toMap() map[string]int {
+	m := make(map[string]int, s.len())
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		for word, n := range shard.m {
+			m[word] = *n
+		}
+		shard.mu.Unlock()
+	}
+	return m
+}
+
+// pruneTo holds every shard's lock while it evicts, so it sees, and
+// leaves, a consistent set of counts.
+func (s *shardedStore) pruneTo(target int, evicted func(word string)) int {
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+		defer s.shards[i].mu.Unlock()
+	}
+	if s.len() <= target {
+		return 0
+	}
+	counts := make([]int, 0, s.len())
+	for i := range s.shards {
+		for _, n := range s.shards[i].m {
+			counts = append(counts, *n)
+		}
+	}
+	threshold := pruneThreshold(counts, target)
+	for i := range s.shards {
+		for word, n := range s.shards[i].m {
+			if *n <= threshold {
+				delete(s.shards[i].m, word)
+				s.unique.Add(-1)
+				if evicted != nil {
+					evicted(word)
+				}
+			}
+		}
+	}
+	return threshold
+}
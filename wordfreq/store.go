@@ -0,0 +1,187 @@
+package wordfreq
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Hash selects the data structure that holds counts while counting. Every
+// choice produces identical results; only speed and memory differ.
+type Hash int
+
+const (
+	// HashFNV is the default open-addressing table with FNV-1a hashing.
+	HashFNV Hash = iota
+	// HashMap is the standard library map[string]int.
+	HashMap
+	// HashXX is the open-addressing table with xxHash32 hashing.
+	HashXX
+	// HashSharded is a set of 256 mutex-guarded maps shared by all
+	// parallel workers, which saves the final merge of per-worker tables
+	// at the cost of locking on every word.
+	HashSharded
+)
+
+// hashNames are the names accepted by ParseHash, indexed by Hash.
+var hashNames = [...]string{HashFNV: "fnv-table", HashMap: "map", HashXX: "xxhash", HashSharded: "sharded"}
+
+func (h Hash) String() string {
+	if h >= 0 && int(h) < len(hashNames) {
+		return hashNames[h]
+	}
+	return fmt.Sprintf("Hash(%d)", int(h))
+}
+
+// ParseHash returns the Hash named by s: "fnv-table", "map", "xxhash" or
+// "sharded".
+func ParseHash(s string) (Hash, error) {
+	for h, name := range hashNames {
+		if s == name {
+			return Hash(h), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown hash %q (want fnv-table, map, xxhash or sharded)", s)
+}
+
+// store holds word counts for a counter. merge is only ever called with a
+// store of the same kind. Only a shardedStore is safe for concurrent use.
+type store interface {
+	add(word []byte, n int)
+	len() int
+	merge(other store)
+	toMap() map[string]int
+	// pruneTo evicts every entry whose count is at or below the smallest
+	// threshold that leaves at most target entries and returns the
+	// threshold, or 0 if nothing was evicted. evicted, if not nil, is
+	// called with each word removed.
+	pruneTo(target int, evicted func(word string)) int
+}
+
+// newWorkerCounters returns the counters for n parallel workers. With
+// HashSharded they all share one store, and merging them only adds up their
+// totals.
+func newWorkerCounters(opts Options, n int) []*counter {
+	counters := make([]*counter, n)
+	for i := range counters {
+		counters[i] = newCounter(opts)
+		if opts.Hash == HashSharded && i > 0 {
+			counters[i].counts = counters[0].counts
+		}
+	}
+	return counters
+}
+
+// newStore returns an empty store of kind h with room for about size
+// unique words.
+func newStore(h Hash, size int) store {
+	switch h {
+	case HashMap:
+		return mapStore(make(map[string]int, size))
+	case HashXX:
+		t := newTable(size)
+		t.xx = true
+		return t
+	case HashSharded:
+		return newShardedStore(size)
+	}
+	return newTable(size)
+}
+
+// mapStore is the HashMap store.
+type mapStore map[string]int
+
+func (m mapStore) add(word []byte, n int) { m[string(word)] += n }
+
+func (m mapStore) len() int { return len(m) }
+
+func (m mapStore) merge(other store) {
+	for word, n := range other.(mapStore) {
+		m[word] += n
+	}
+}
+
+func (m mapStore) toMap() map[string]int { return m }
+
+func (m mapStore) pruneTo(target int, evicted func(word string)) int {
+	if len(m) <= target {
+		return 0
+	}
+	counts := make([]int, 0, len(m))
+	for _, n := range m {
+		counts = append(counts, n)
+	}
+	threshold := pruneThreshold(counts, target)
+	for word, n := range m {
+		if n <= threshold {
+			delete(m, word)
+			if evicted != nil {
+				evicted(word)
+			}
+		}
+	}
+	return threshold
+}
+
+// pruneThreshold returns the smallest count such that at most target of
+// counts are above it. counts is sorted in place.
+func pruneThreshold(counts []int, target int) int {
+	slices.Sort(counts)
+	return counts[len(counts)-target-1]
+}
+
+// xxhash32 is the 32-bit xxHash of data with seed 0.
+func xxhash32(data []byte) uint32 {
+	const (
+		prime1 uint32 = 2654435761
+		prime2 uint32 = 2246822519
+		prime3 uint32 = 3266489917
+		prime4 uint32 = 668265263
+		prime5 uint32 = 374761393
+	)
+	round := func(acc, input uint32) uint32 {
+		acc += input * prime2
+		acc = acc<<13 | acc>>19
+		return acc * prime1
+	}
+	read32 := func(b []byte) uint32 {
+		return uint32(b[0]) | uint32(b[1])<<8 | uint32(b[2])<<16 | uint32(b[3])<<24
+	}
+
+	var seed uint32
+	n := len(data)
+	var h uint32
+	if n >= 16 {
+		v1 := seed + prime1 + prime2
+		v2 := seed + prime2
+		v3 := seed
+		v4 := seed - prime1
+		for len(data) >= 16 {
+			v1 = round(v1, read32(data[0:]))
+			v2 = round(v2, read32(data[4:]))
+			v3 = round(v3, read32(data[8:]))
+			v4 = round(v4, read32(data[12:]))
+			data = data[16:]
+		}
+		h = (v1<<1 | v1>>31) + (v2<<7 | v2>>25) + (v3<<12 | v3>>20) + (v4<<18 | v4>>14)
+	} else {
+		h = seed + prime5
+	}
+	h += uint32(n)
+
+	for len(data) >= 4 {
+		h += read32(data) * prime3
+		h = (h<<17 | h>>15) * prime4
+		data = data[4:]
+	}
+	for _, b := range data {
+		h += uint32(b) * prime5
+		h = (h<<11 | h>>21) * prime1
+	}
+
+	h ^= h >> 15
+	h *= prime2
+	h ^= h >> 13
+	h *= prime3
+	h ^= h >> 16
+	return h
+}
@@ -0,0 +1,63 @@
+package wordfreq
+
+import (
+	"bytes"
+	"maps"
+	"testing"
+	"unsafe"
+)
+
+// hashes are the Hash kinds every store test runs against.
+var hashes = []Hash{HashFNV, HashMap, HashXX, HashSharded}
+
+func TestStoreReusedBuffer(t *testing.T) {
+	for _, h := range hashes {
+		t.Run(h.String(), func(t *testing.T) {
+			s := newStore(h, 16)
+			buf := make([]byte, 0, MaxWordLength)
+			for _, word := range []string{"alpha", "beta", "alpha", "be"} {
+				buf = append(buf[:0], word...)
+				s.add(buf, 1)
+			}
+			// The bytes of the last word added are overwritten, so a key
+			// aliasing buf would show up here.
+			copy(buf[:cap(buf)], "zzzzzzzz")
+			want := map[string]int{"alpha": 2, "beta": 1, "be": 1}
+			if got := s.toMap(); !maps.Equal(got, want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestStoreAddExistingNoAlloc(t *testing.T) {
+	// HashMap is left out: the standard map copies the key of every
+	// assignment, which is what the other stores exist to avoid.
+	for _, h := range []Hash{HashFNV, HashXX, HashSharded} {
+		t.Run(h.String(), func(t *testing.T) {
+			s := newStore(h, 16)
+			buf := []byte("alpha")
+			s.add(buf, 1)
+			if allocs := testing.AllocsPerRun(100, func() { s.add(buf, 1) }); allocs != 0 {
+				t.Errorf("adding a word already held made %v allocations, want 0", allocs)
+			}
+		})
+	}
+}
+
+func BenchmarkTallyHash(b *testing.B) {
+	for _, h := range hashes {
+		b.Run(h.String(), func(b *testing.B) {
+			benchmarkCount(b, sample(), func(data []byte) error {
+				_, err := Tally(bytes.NewReader(data), Options{Hash: h}, 1)
+				return err
+			})
+		})
+	}
+}
+
+func TestShardSize(t *testing.T) {
+	if size := unsafe.Sizeof(shardMap{}); size != cacheLineSize {
+		t.Errorf("a shardMap is %d bytes, want one %d-byte cache line", size, cacheLineSize)
+	}
+}
@@ -0,0 +1,138 @@
+package wordfreq
+
+import "bytes"
+
+// table is an open-addressing hash table keyed by byte slices, using FNV-1a
+// (or, for HashXX, xxHash32) hashing and linear probing over a power-of-two
+// slot array, like the table in the C reference implementation. Keys are
+// copied into a shared arena, so counting a word never allocates a string;
+// strings are only created once per unique word when the table is
+// converted to a map.
+type table struct {
+	slots []slot
+	arena []byte
+	used  int
+	xx    bool // hash with xxhash32 instead of FNV-1a
+}
+
+// slot is one table entry. A count of 0 marks an empty slot.
+type slot struct {
+	hash  uint32
+	n     uint32 // key length
+	off   int    // key offset in arena
+	count int
+}
+
+func newTable(capacity int) *table {
+	size := 1
+	for size*7 < capacity*10 { // room for capacity keys below the 70% load of addHashed
+		size <<= 1
+	}
+	return &table{slots: make([]slot, size)}
+}
+
+// add adds n occurrences of word, copying word if it is new.
+func (t *table) add(word []byte, n int) {
+	if t.xx {
+		t.addHashed(word, xxhash32(word), n)
+		return
+	}
+	t.addHashed(word, fnv1aHash(word), n)
+}
+
+func (t *table) len() int {
+	return t.used
+}
+
+func (t *table) addHashed(word []byte, hash uint32, n int) {
+	if t.used*10 >= len(t.slots)*7 {
+		t.grow()
+	}
+
+	mask := uint32(len(t.slots) - 1)
+	for i := hash & mask; ; i = (i + 1) & mask {
+		s := &t.slots[i]
+		if s.count == 0 {
+			*s = slot{hash: hash, n: uint32(len(word)), off: len(t.arena), count: n}
+			t.arena = append(t.arena, word...)
+			t.used++
+			return
+		}
+		if s.hash == hash && int(s.n) == len(word) && bytes.Equal(t.key(s), word) {
+			s.count += n
+			return
+		}
+	}
+}
+
+func (t *table) key(s *slot) []byte {
+	return t.arena[s.off : s.off+int(s.n)]
+}
+
+// grow doubles the slot array and reinserts every entry.
+func (t *table) grow() {
+	old := t.slots
+	t.slots = make([]slot, 2*len(old))
+	mask := uint32(len(t.slots) - 1)
+	for _, s := range old {
+		if s.count == 0 {
+			continue
+		}
+		i := s.hash & mask
+		for t.slots[i].count != 0 {
+			i = (i + 1) & mask
+		}
+		t.slots[i] = s
+	}
+}
+
+// merge adds every entry of other, a table using the same hash, to t.
+func (t *table) merge(o store) {
+	other := o.(*table)
+	for i := range other.slots {
+		s := &other.slots[i]
+		if s.count != 0 {
+			t.addHashed(other.key(s), s.hash, s.count)
+		}
+	}
+}
+
+// toMap returns the table contents as a map of word to count.
+func (t *table) toMap() map[string]int {
+	m := make(map[string]int, t.used)
+	for i := range t.slots {
+		s := &t.slots[i]
+		if s.count != 0 {
+			m[string(t.key(s))] = s.count
+		}
+	}
+	return m
+}
+
+// pruneTo implements store.pruneTo, compacting the arena.
+func (t *table) pruneTo(target int, evicted func(word string)) int {
+	if t.used <= target {
+		return 0
+	}
+
+	counts := make([]int, 0, t.used)
+	for i := range t.slots {
+		if t.slots[i].count != 0 {
+			counts = append(counts, t.slots[i].count)
+		}
+	}
+	threshold := pruneThreshold(counts, target)
+
+	old := *t
+	*t = table{slots: make([]slot, len(old.slots)), arena: make([]byte, 0, len(old.arena)/2), xx: old.xx}
+	for i := range old.slots {
+		s := &old.slots[i]
+		switch {
+		case s.count > threshold:
+			t.addHashed(old.key(s), s.hash, s.count)
+		case s.count != 0 && evicted != nil:
+			evicted(string(old.key(s)))
+		}
+	}
+	return threshold
+}
@@ -0,0 +1,76 @@
+package wordfreq
+
+import (
+	"bytes"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// sampleWords are the words of sample, in order, as the counter gets them.
+var sampleWords = sync.OnceValue(func() [][]byte {
+	var words [][]byte
+	err := ScanWords(bytes.NewReader(sample()), Options{}, func(word []byte) {
+		words = append(words, bytes.Clone(word))
+	})
+	if err != nil {
+		panic(err)
+	}
+	return words
+})
+
+func BenchmarkStore(b *testing.B) {
+	words := sampleWords()
+	size := 0
+	for _, word := range words {
+		size += len(word)
+	}
+	for _, h := range hashes {
+		b.Run(h.String(), func(b *testing.B) {
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				s := newStore(h, initialMapSize)
+				for _, word := range words {
+					s.add(word, 1)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkTableGrow adds distinct words to tables of several initial
+// sizes, showing what presizing with Options.MapSize saves in rehashing.
+func BenchmarkTableGrow(b *testing.B) {
+	const unique = 200000
+	words := make([][]byte, unique)
+	for i := range words {
+		words[i] = strconv.AppendInt([]byte("w"), int64(i), 36)
+	}
+	for _, size := range []int{16, initialMapSize, 2 * unique} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				t := newTable(size)
+				for _, word := range words {
+					t.add(word, 1)
+				}
+			}
+		})
+	}
+}
+
+// TestTableCapacity checks that a table takes the number of unique words
+// it was created for without growing.
+func TestTableCapacity(t *testing.T) {
+	for _, capacity := range []int{1, 7, 8, 1000, initialMapSize} {
+		tab := newTable(capacity)
+		slots := len(tab.slots)
+		for i := range capacity {
+			tab.add(strconv.AppendInt([]byte("w"), int64(i), 36), 1)
+		}
+		if len(tab.slots) != slots {
+			t.Errorf("newTable(%d) grew from %d to %d slots for %d words", capacity, slots, len(tab.slots), capacity)
+		}
+	}
+}
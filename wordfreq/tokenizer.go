@@ -0,0 +1,390 @@
+package wordfreq
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+// rightSingleQuote is U+2019, the typographic apostrophe, and hyphen is
+// U+2010, the typographic hyphen.
+const (
+	rightSingleQuote = "’"
+	hyphen           = "‐"
+)
+
+// joiner is punctuation that is kept inside a word when it sits between
+// two letters. Each form is recorded in the word as the ASCII character as.
+type joiner struct {
+	text []byte
+	as   rune
+}
+
+// joinersFor returns the joiners enabled by opts.
+func joinersFor(opts Options) []joiner {
+	var joiners []joiner
+	if opts.Contractions {
+		joiners = append(joiners, joiner{[]byte("'"), '\''}, joiner{[]byte(rightSingleQuote), '\''})
+	}
+	if opts.Hyphens {
+		joiners = append(joiners, joiner{[]byte("-"), '-'}, joiner{[]byte(hyphen), '-'})
+	}
+	return joiners
+}
+
+// tokenizer splits input into words according to Options. It handles every
+// mode except the default ASCII-letters-only case, which Count runs through
+// a specialized inline loop.
+type tokenizer struct {
+	opts    Options
+	class   *byteClass
+	joiners []joiner
+	limit   int    // longest word kept, in bytes or, with runes, characters
+	runes   bool   // lengths are in characters (Options.Unicode)
+	word    []byte // normalized form of the last word returned by next
+	n       int    // characters in word
+	long    bool   // the last word was truncated to limit
+
+	// spell keeps orig, the last word as written before case folding, for
+	// Options.Spelling.
+	spell bool
+	orig  []byte
+}
+
+func newTokenizer(opts Options) *tokenizer {
+	limit := opts.wordLimit()
+	return &tokenizer{opts: opts, class: opts.class(), joiners: joinersFor(opts), limit: limit, runes: opts.Unicode,
+		word: make([]byte, 0, limit), spell: opts.Spelling != SpellingNone}
+}
+
+// ExtractWord finds the next word in data at or after start, normalizes it
+// into wordBuf and returns it along with the position just past the word.
+// found is false when no word remains or the word falls outside the length
+// limits in opts (MaxWordLength when opts.MaxLength is 0), which count
+// characters with opts.Unicode.
+func ExtractWord(data []byte, start int, wordBuf []byte, opts Options) (word []byte, newPos int, found bool) {
+	t := tokenizer{opts: opts, class: opts.class(), joiners: joinersFor(opts), limit: opts.wordLimit(), runes: opts.Unicode,
+		word: wordBuf[:0]}
+	_, end, ok, _ := t.next(data, start, true)
+	if !ok || t.long || t.length() < opts.MinLength {
+		return nil, end, false
+	}
+	return t.word, end, true
+}
+
+// scan counts the words in data. Unless atEOF is set, a word or an
+// incomplete character that runs to the end of data is not counted; it is
+// returned as rest so the caller can prepend it to the next chunk.
+func (t *tokenizer) scan(data []byte, atEOF bool, c *counter) (rest []byte) {
+	pos := 0
+	for {
+		start, end, ok, more := t.next(data, pos, atEOF)
+		if more {
+			return data[start:]
+		}
+		if !ok {
+			return nil
+		}
+		pos = end
+		if (t.long && !c.truncate) || t.length() < c.minLen {
+			continue
+		}
+		orig := t.word
+		if t.spell {
+			orig = t.orig
+		}
+		c.add(t.word, orig)
+	}
+}
+
+// next finds the first word in data at or after pos, leaving its normalized
+// form in t.word. ok is false when data holds no further word. When atEOF
+// is false and data ends before the word (or a multibyte character) is
+// complete, next reports more and start is the offset at which scanning
+// must resume once further input has been appended.
+func (t *tokenizer) next(data []byte, pos int, atEOF bool) (start, end int, ok, more bool) {
+	if t.opts.Delimiter != 0 {
+		return t.nextToken(data, pos, atEOF)
+	}
+	n := len(data)
+
+	for pos < n {
+		if !atEOF && !t.complete(data[pos:]) {
+			return pos, n, false, true
+		}
+		r, size := t.decode(data[pos:])
+		if t.isLetter(r) {
+			break
+		}
+		if t.opts.Emoji && r >= utf8.RuneSelf && t.opts.Unicode {
+			end, ok, more := emoji(data, pos, atEOF)
+			if more {
+				return pos, n, false, true
+			}
+			if ok {
+				t.setEmoji(data[pos:end])
+				return pos, end, true, false
+			}
+		}
+		pos += size
+	}
+
+	if pos >= n {
+		return n, n, false, false
+	}
+
+	start = pos
+	t.word = t.word[:0]
+	t.orig = t.orig[:0]
+	t.n = 0
+	t.long = false
+
+	for {
+		for pos < n {
+			if !atEOF && !t.complete(data[pos:]) {
+				return start, n, false, true
+			}
+			r, size := t.decode(data[pos:])
+			if !t.isLetter(r) {
+				break
+			}
+			t.appendRune(r)
+			pos += size
+		}
+
+		if pos == n {
+			if !atEOF {
+				return start, n, false, true
+			}
+			break
+		}
+
+		size, as, needMore := t.joiner(data[pos:], atEOF)
+		if needMore {
+			return start, n, false, true
+		}
+		if size == 0 {
+			break
+		}
+		t.appendRune(as)
+		pos += size
+	}
+
+	return start, pos, true, false
+}
+
+// nextToken is next for Options.Delimiter: it finds the first non-empty
+// token between delimiters at or after pos.
+func (t *tokenizer) nextToken(data []byte, pos int, atEOF bool) (start, end int, ok, more bool) {
+	delim := t.opts.Delimiter
+	for pos < len(data) {
+		start = pos
+		i := bytes.IndexByte(data[pos:], delim)
+		if i < 0 {
+			if !atEOF {
+				return start, len(data), false, true
+			}
+			i = len(data) - pos
+		}
+		pos += i
+		token := data[start:pos]
+		if delim == '\n' {
+			token = bytes.TrimSuffix(token, []byte{'\r'})
+		}
+		if len(token) > 0 {
+			t.setToken(token)
+			return start, pos, true, false
+		}
+		pos++
+	}
+	return len(data), len(data), false, false
+}
+
+// trimCarry shortens rest, a word or token that scan returned because it
+// ran to the end of the data, once it is too long to be kept whole. What
+// is left is a prefix long enough to exceed t.limit bytes when folded
+// (no character folds to less than a quarter of its bytes), followed,
+// for a word, by whatever comes after its last letter. The middle that is
+// cut out runs from just after one letter to just after another, so the
+// rest of the word reads the same and the word is kept, truncated, or
+// skipped as before.
+func (t *tokenizer) trimCarry(rest []byte) []byte {
+	keep := utf8.UTFMax * (t.limit + 1)
+	if len(rest) <= 2*keep {
+		return rest
+	}
+	for !utf8.RuneStart(rest[keep]) {
+		keep++
+	}
+	if t.opts.Delimiter != 0 {
+		return rest[:keep]
+	}
+	if r, _ := t.decode(rest); !t.isLetter(r) {
+		return rest // an emoji cluster
+	}
+
+	head := keep
+	for head < len(rest) {
+		r, size := t.decode(rest[head:])
+		head += size
+		if t.isLetter(r) {
+			break
+		}
+	}
+	tail := len(rest)
+	for tail > head {
+		r, size := rune(rest[tail-1]), 1
+		if t.opts.Unicode {
+			r, size = utf8.DecodeLastRune(rest[:tail])
+		}
+		if t.isLetter(r) {
+			break
+		}
+		tail -= size
+	}
+	if tail <= head {
+		return rest
+	}
+	return append(rest[:head:head], rest[tail:]...)
+}
+
+// setToken makes token the current word, case-folded and truncated like
+// a word made of letters. Invalid UTF-8 is kept byte for byte, and counts
+// as one character toward the limit.
+func (t *tokenizer) setToken(token []byte) {
+	t.word = t.word[:0]
+	t.orig = t.orig[:0]
+	t.n = 0
+	t.long = false
+	var scratch [utf8.UTFMax]byte
+	for i := 0; i < len(token); {
+		r, size := utf8.DecodeRune(token[i:])
+		raw := token[i : i+size]
+		folded := raw
+		switch {
+		case t.opts.CaseSensitive:
+		case r < utf8.RuneSelf:
+			scratch[0] = lowerTable[r]
+			folded = scratch[:1]
+		case t.opts.Unicode && r != utf8.RuneError:
+			folded = utf8.AppendRune(scratch[:0], unicode.ToLower(r))
+		}
+		if t.overflows(len(folded)) {
+			t.long = true
+			return
+		}
+		t.word = append(t.word, folded...)
+		t.n++
+		if t.spell {
+			t.orig = append(t.orig, raw...)
+		}
+		i += size
+	}
+}
+
+// setEmoji makes the emoji cluster e the current word, truncated at
+// t.limit like any other.
+func (t *tokenizer) setEmoji(e []byte) {
+	t.word = t.word[:0]
+	t.orig = t.orig[:0]
+	t.n = 0
+	t.long = false
+	for _, r := range string(e) {
+		t.appendRune(r)
+	}
+}
+
+// joiner reports the encoded length of a word-internal joiner at the start
+// of b, such as the apostrophe in "don't", and the character it is recorded
+// as; size is 0 if b does not start with one that is followed by a letter.
+func (t *tokenizer) joiner(b []byte, atEOF bool) (size int, as rune, more bool) {
+	for _, j := range t.joiners {
+		if bytes.HasPrefix(b, j.text) {
+			size, as = len(j.text), j.as
+			break
+		}
+		if !atEOF && len(b) < len(j.text) && bytes.HasPrefix(j.text, b) {
+			return 0, 0, true
+		}
+	}
+	if size == 0 {
+		return 0, 0, false
+	}
+
+	next := b[size:]
+	if len(next) == 0 || (!atEOF && !t.complete(next)) {
+		return 0, 0, !atEOF
+	}
+	if r, _ := t.decode(next); !t.isLetter(r) {
+		return 0, 0, false
+	}
+	return size, as, false
+}
+
+// complete reports whether b starts with a whole character.
+func (t *tokenizer) complete(b []byte) bool {
+	return !t.opts.Unicode || utf8.FullRune(b)
+}
+
+// decode returns the character at the start of b and its encoded length.
+// In ASCII mode every byte is one character. Invalid UTF-8 decodes to
+// utf8.RuneError, which is never a letter.
+func (t *tokenizer) decode(b []byte) (rune, int) {
+	if !t.opts.Unicode {
+		return rune(b[0]), 1
+	}
+	return utf8.DecodeRune(b)
+}
+
+// isLetter reports whether r is a word character: an ASCII byte in the
+// class selected by Options, or with Options.Unicode any other letter.
+func (t *tokenizer) isLetter(r rune) bool {
+	if r < utf8.RuneSelf {
+		return t.class[r]
+	}
+	return t.opts.Unicode && unicode.IsLetter(r)
+}
+
+func (t *tokenizer) lower(r rune) rune {
+	if t.opts.CaseSensitive {
+		return r
+	}
+	if t.opts.Unicode {
+		return unicode.ToLower(r)
+	}
+	return rune(lowerTable[byte(r)])
+}
+
+// appendRune adds r, case-folded, to the current word, truncating at
+// t.limit without splitting a multibyte character. Once a character has
+// been dropped the rest of the word is too, so the kept part is a prefix.
+// The spelling in t.orig is cut at the same character.
+func (t *tokenizer) appendRune(r rune) {
+	folded := t.lower(r)
+	if t.long || t.overflows(utf8.RuneLen(folded)) {
+		t.long = true
+		return
+	}
+	t.word = utf8.AppendRune(t.word, folded)
+	t.n++
+	if t.spell {
+		t.orig = utf8.AppendRune(t.orig, r)
+	}
+}
+
+// overflows reports whether a character of size bytes no longer fits in
+// the current word.
+func (t *tokenizer) overflows(size int) bool {
+	if t.runes {
+		return t.n >= t.limit
+	}
+	return len(t.word)+size > t.limit
+}
+
+// length returns the length of the current word as limit counts it.
+func (t *tokenizer) length() int {
+	if t.runes {
+		return t.n
+	}
+	return len(t.word)
+}
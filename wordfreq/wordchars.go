@@ -0,0 +1,97 @@
+package wordfreq
+
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
+// byteClass marks the ASCII bytes that are word characters.
+type byteClass [256]bool
+
+// Default classes for Options without WordChars.
+var (
+	letterBytes   = newByteClass("a-zA-Z")
+	alphanumBytes = newByteClass("a-zA-Z0-9")
+)
+
+func newByteClass(ranges string) *byteClass {
+	var class byteClass
+	for i := 0; i+2 < len(ranges); i += 3 {
+		for b := ranges[i]; b <= ranges[i+2]; b++ {
+			class[b] = true
+		}
+	}
+	return &class
+}
+
+// WordChars is a custom set of ASCII word characters; see ParseWordChars.
+type WordChars struct {
+	spec   string
+	class  byteClass
+	folded byteClass // class with both cases of each letter
+}
+
+// ParseWordChars compiles spec, a list of ASCII characters and ranges such
+// as "a-z0-9'-", into a set of word characters for Options.WordChars. A
+// hyphen is literal at the start or end of spec, and a backslash makes
+// the next character literal. Unless counting is case-sensitive, a letter
+// in the set also admits its other case, since words are lowercased anyway.
+func ParseWordChars(spec string) (*WordChars, error) {
+	w := &WordChars{spec: spec}
+	var chars []byte
+	var literal []bool
+	for i := 0; i < len(spec); i++ {
+		b := spec[i]
+		if b >= utf8.RuneSelf {
+			return nil, fmt.Errorf("word characters must be ASCII: %q", spec)
+		}
+		if b == '\\' && i+1 < len(spec) {
+			i++
+			chars, literal = append(chars, spec[i]), append(literal, true)
+			continue
+		}
+		chars, literal = append(chars, b), append(literal, false)
+	}
+	if len(chars) == 0 {
+		return nil, fmt.Errorf("empty word character set")
+	}
+
+	for i := 0; i < len(chars); i++ {
+		lo, hi := chars[i], chars[i]
+		if i+2 < len(chars) && chars[i+1] == '-' && !literal[i+1] {
+			hi = chars[i+2]
+			i += 2
+			if hi < lo {
+				return nil, fmt.Errorf("invalid range %c-%c in %q", lo, hi, spec)
+			}
+		}
+		for b := int(lo); b <= int(hi); b++ {
+			w.class[b] = true
+		}
+	}
+	w.folded = w.class
+	for b := 'a'; b <= 'z'; b++ {
+		if w.class[b] || w.class[b-'a'+'A'] {
+			w.folded[b], w.folded[b-'a'+'A'] = true, true
+		}
+	}
+	return w, nil
+}
+
+// String returns the spec w was parsed from.
+func (w *WordChars) String() string {
+	return w.spec
+}
+
+// class returns the ASCII word characters selected by o.
+func (o Options) class() *byteClass {
+	switch {
+	case o.WordChars != nil && o.CaseSensitive:
+		return &o.WordChars.class
+	case o.WordChars != nil:
+		return &o.WordChars.folded
+	case o.Digits:
+		return alphanumBytes
+	}
+	return letterBytes
+}
@@ -0,0 +1,690 @@
+// Package wordfreq implements the word frequency counting engine used by the
+// Go benchmark implementation.
+//
+// A word is a maximal run of ASCII letters, lowercased, unless Options
+// selects a different definition. See the repository README for the
+// cross-language word definition this matches.
+package wordfreq
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"regexp"
+	"sort"
+)
+
+const (
+	initialMapSize = 16384
+	bufferSize     = 64 * 1024 // 64KB
+
+	// MaxWordLength is the longest word, in bytes (in characters with
+	// Options.Unicode), that is kept intact when Options.MaxLength is not
+	// set. Longer words are truncated.
+	MaxWordLength = 100
+)
+
+// Options controls how words are recognized while counting. The zero
+// value selects the default ASCII-letters-only, case-insensitive behavior.
+type Options struct {
+	// Unicode selects UTF-8 decoding with unicode.IsLetter/unicode.ToLower
+	// instead of the default ASCII-only byte path.
+	Unicode bool
+
+	// Contractions keeps an apostrophe (ASCII ' or U+2019) that sits
+	// between two letters as part of the word, so "don't" is one word.
+	// Both forms are recorded as the ASCII apostrophe. Leading and
+	// trailing apostrophes are still separators.
+	Contractions bool
+
+	// Emoji also counts each emoji, with the skin tone, ZWJ sequence or
+	// flag it forms part of, as a word of its own; see emoji for what is
+	// recognized. A pictograph next to a letter still ends the word. It
+	// has no effect without Unicode.
+	Emoji bool
+
+	// Hyphens keeps a hyphen (ASCII - or U+2010) that sits between two
+	// letters as part of the word, so "well-being" is one word. Both forms
+	// are recorded as the ASCII hyphen. Leading, trailing and doubled
+	// hyphens ("a--b") are still separators.
+	Hyphens bool
+
+	// Digits treats the ASCII digits 0-9 as word characters, so
+	// alphanumeric runs such as "error404" and "2023" are single words.
+	// Digits are never case-folded.
+	Digits bool
+
+	// WordChars, when set, replaces the ASCII letters (and Digits) as the
+	// set of ASCII word characters. With Unicode, non-ASCII letters are
+	// still word characters as well.
+	WordChars *WordChars
+
+	// StopWords lists words that are skipped entirely: they are neither
+	// counted nor included in the total. Keys must be in the normalized
+	// form produced under the same Options; ReadWordSet builds such a set.
+	StopWords map[string]struct{}
+
+	// OnlyWords, when non-nil, is an allow-list: words not in it are
+	// skipped as if they were stop words. Keys are normalized as for
+	// StopWords.
+	OnlyWords map[string]struct{}
+
+	// Match, when set, skips words it does not match, and Exclude skips
+	// words it does match, both like stop words and after them. They see
+	// the normalized word. Matching every word is slow, so they are best
+	// left nil unless needed.
+	Match, Exclude *regexp.Regexp
+
+	// CaseSensitive disables lowercasing, so "Apple" and "apple" are
+	// counted as different words.
+	CaseSensitive bool
+
+	// MinLength skips words shorter than this many bytes, or characters
+	// with Unicode, so that a limit means the same for any script.
+	MinLength int
+
+	// MaxLength skips words longer than this many bytes, or characters
+	// with Unicode. When 0, words are never skipped for length but are
+	// truncated to MaxWordLength.
+	MaxLength int
+
+	// MaxUnique bounds memory for huge vocabularies: whenever more than
+	// MaxUnique distinct words are held, the lowest-count words are
+	// evicted until at most half that many remain. Heavy hitters stay
+	// accurate while rare words become approximate; see Result.Pruned.
+	// With more than one worker, which words are evicted depends on how
+	// blocks were shared out, so pruned counts can differ between runs.
+	// 0 means unbounded.
+	MaxUnique int
+
+	// Lengths collects a histogram of counted word lengths in bytes (in
+	// characters with Unicode), returned in Result.Lengths.
+	Lengths bool
+
+	// NGram counts sequences of this many consecutive words, joined by a
+	// single space, instead of single words. Stop-word and length filters
+	// apply to the individual words before grams are formed, and
+	// Result.TotalWords counts grams. Grams span chunk boundaries but not
+	// inputs, and counting is always sequential. 0 or 1 counts single words.
+	NGram int
+
+	// Cooccur counts pairs of different words at most this many words
+	// apart instead of single words, keyed by the two words in byte order
+	// joined by a single space, so "b ... a" and "a ... b" are one pair.
+	// Each pair of positions in range is one occurrence, and
+	// Result.TotalWords counts them. The filters apply to the individual
+	// words first, as for NGram, which is ignored when Cooccur is set;
+	// the window spans chunk boundaries but not inputs, and counting is
+	// always sequential. 0 counts single words.
+	Cooccur int
+
+	// BufferSize is the size in bytes of each read when counting
+	// sequentially. 0 selects 64KB; smaller values below MaxWordLength
+	// are raised to it.
+	BufferSize int
+
+	// TextStats also counts lines, bytes and characters, like wc, into
+	// Result.Lines, Result.Bytes and Result.Chars.
+	TextStats bool
+
+	// Hash selects how counts are stored while counting; see Hash.
+	Hash Hash
+
+	// MapSize is the initial capacity of the count store, as for
+	// make(map[string]int, MapSize): the number of unique words it takes
+	// before its first rehash. Each parallel worker has a store this size,
+	// unless they share one under HashSharded. 0 selects 16384.
+	MapSize int
+
+	// Sample, when between 0 and 1, counts only the words whose FNV-1a
+	// hash falls in that fraction of the hash space, after stop words are
+	// removed. A given word is always or never counted, so the counts of
+	// the words kept are exact and their shares of TotalWords estimate
+	// those of the whole input. With NGram, grams are formed from the
+	// sampled words. 0 or 1 counts every word.
+	Sample float64
+
+	// CountOnly counts words without keeping them, for a plain total:
+	// Result.Counts stays empty and TotalWords, Lengths and the text
+	// statistics are filled in as usual, with no map inserts at all.
+	CountOnly bool
+
+	// Delimiter, when not 0, replaces word recognition altogether: the
+	// input is split on this ASCII byte and each non-empty token between
+	// delimiters is counted verbatim, case-folded unless CaseSensitive
+	// (non-ASCII letters only with Unicode). With '\n', a trailing '\r'
+	// is dropped from each line. The letter, joiner and Digits options
+	// have no effect.
+	Delimiter byte
+
+	// RecordSep, when not 0, promises that no word crosses this byte, as
+	// '\n' does for text whose words never span lines. The input is then
+	// only ever cut just after it: parallel blocks end on a separator, found
+	// with bytes.LastIndexByte instead of a scan for any byte that cannot
+	// be part of a word, and sequential counting reads whole records, so no
+	// partial word is carried from one read to the next. A word that does
+	// cross the separator is split in two rather than detected.
+	RecordSep byte
+
+	// Spelling records how each counted word was written before case
+	// folding, in Result.Spellings; see Spelling. It has no effect with
+	// NGram or Cooccur.
+	Spelling Spelling
+
+	// LimitBytes, when positive, stops counting after about this many
+	// bytes of input: the word running at that point is read to its end,
+	// and the rest of the input is left unread. Result.Limited reports
+	// that the limit cut the input short.
+	LimitBytes int64
+
+	// FirstSeen records the order in which words (or grams) were first
+	// counted, in Result.FirstSeen. Counting is then always sequential.
+	FirstSeen bool
+
+	// Normalizer, when set, rewrites each word before the filters and
+	// the count see it; see Normalizer.
+	Normalizer Normalizer
+
+	// Logger, when not nil and enabled for slog.LevelDebug, is told how
+	// the input is cut up: the partial word carried from one read to the
+	// next, and each block or segment handed to a parallel worker. The
+	// counts are unaffected.
+	Logger *slog.Logger
+}
+
+// Spelling selects which original spelling of each word is kept when
+// words that differ only in case are counted together.
+type Spelling int
+
+const (
+	// SpellingNone keeps no spellings.
+	SpellingNone Spelling = iota
+
+	// SpellingFirst keeps the spelling of each word's first occurrence,
+	// which needs the input in order, so counting is always sequential.
+	SpellingFirst
+
+	// SpellingCommon also counts every spelling of each word, so the most
+	// common one can be told apart; see Spellings.Common.
+	SpellingCommon
+)
+
+// Spellings records how one counted word was written in the input.
+type Spellings struct {
+	// First is the spelling of the word's first occurrence. Under
+	// SpellingCommon with more than one worker, it is only the first in
+	// some part of the input.
+	First string
+
+	// Counts holds the occurrences of each spelling. It is nil unless
+	// Options.Spelling is SpellingCommon.
+	Counts map[string]int
+}
+
+// Common returns the spelling counted most often, alphabetically first on
+// a tie, or First when the spellings were not counted.
+func (s *Spellings) Common() string {
+	best, bestN := s.First, 0
+	for spelling, n := range s.Counts {
+		if n > bestN || (n == bestN && spelling < best) {
+			best, bestN = spelling, n
+		}
+	}
+	return best
+}
+
+// mergeSpellings adds the spellings in src to dst, which holds the earlier
+// input, so its first spellings stand, and returns dst.
+func mergeSpellings(dst, src map[string]*Spellings) map[string]*Spellings {
+	if dst == nil && len(src) > 0 {
+		dst = make(map[string]*Spellings, len(src))
+	}
+	for word, s := range src {
+		d, ok := dst[word]
+		if !ok {
+			dst[word] = s
+			continue
+		}
+		for spelling, n := range s.Counts {
+			if d.Counts == nil {
+				d.Counts = make(map[string]int, len(s.Counts))
+			}
+			d.Counts[spelling] += n
+		}
+	}
+	return dst
+}
+
+// debugLogger returns Logger if it takes debug records, or nil, so that
+// the reading loops log nothing, and box no arguments, unless asked to.
+func (o Options) debugLogger() *slog.Logger {
+	if o.Logger == nil || !o.Logger.Enabled(context.Background(), slog.LevelDebug) {
+		return nil
+	}
+	return o.Logger
+}
+
+// wordLimit returns the length of a word that is kept: bytes, or
+// characters with Unicode.
+func (o Options) wordLimit() int {
+	if o.MaxLength > 0 {
+		return o.MaxLength
+	}
+	return MaxWordLength
+}
+
+// mapSize returns the initial capacity of the count store.
+func (o Options) mapSize() int {
+	if o.MapSize > 0 {
+		return o.MapSize
+	}
+	return initialMapSize
+}
+
+// readSize returns the chunk size used by the sequential reader loop.
+func (o Options) readSize() int {
+	if o.BufferSize == 0 {
+		return bufferSize
+	}
+	return max(o.BufferSize, MaxWordLength)
+}
+
+// sampleThreshold returns the bound below which a word's hash is kept
+// under Options.Sample, and false when every word is kept.
+func (o Options) sampleThreshold() (uint32, bool) {
+	if o.Sample <= 0 || o.Sample >= 1 {
+		return 0, false
+	}
+	return uint32(o.Sample * (1 << 32)), true
+}
+
+// sequential reports whether opts need the input counted in order by a
+// single counter, whatever the number of workers asked for.
+func (o Options) sequential() bool {
+	return o.NGram > 1 || o.Cooccur > 0 || o.Spelling == SpellingFirst || o.FirstSeen
+}
+
+// fastPath reports whether the default ASCII byte loop in Count can be
+// used instead of the general tokenizer.
+func (o Options) fastPath() bool {
+	return !o.Unicode && !o.Contractions && !o.Hyphens && o.Delimiter == 0
+}
+
+// WordCount is a word paired with its number of occurrences.
+type WordCount struct {
+	Word  string
+	Count int
+}
+
+// fnv1aHash is the 32-bit FNV-1a hash used by the word table.
+func fnv1aHash(data []byte) uint32 {
+	hash := uint32(2166136261)
+	for _, b := range data {
+		hash ^= uint32(b)
+		hash *= 16777619
+	}
+	return hash
+}
+
+// lowerTable maps each byte to its ASCII lowercase form, replacing a range
+// check per byte on the hot path. Bytes other than A-Z map to themselves.
+var lowerTable [256]byte
+
+func init() {
+	for i := range lowerTable {
+		b := byte(i)
+		if b >= 'A' && b <= 'Z' {
+			b += 'a' - 'A'
+		}
+		lowerTable[i] = b
+	}
+}
+
+// scanASCII is the default fast path: it counts maximal runs of ASCII word
+// characters (letters unless Options says otherwise) in data, lowercased
+// unless the counter is case-sensitive. Like tokenizer.scan, a word that
+// runs to the end of data is returned as rest unless atEOF is set. wordBuf
+// must have a capacity of at least opts.wordLimit().
+func scanASCII(data []byte, atEOF bool, c *counter, wordBuf []byte) (rest []byte) {
+	pos := 0
+	dataLen := len(data)
+	isWord := c.class
+
+	for pos < dataLen {
+		for pos < dataLen && !isWord[data[pos]] {
+			pos++
+		}
+
+		if pos >= dataLen {
+			break
+		}
+
+		wordStart := pos
+		for pos < dataLen && isWord[data[pos]] {
+			pos++
+		}
+
+		if pos == dataLen && !atEOF {
+			return data[wordStart:]
+		}
+
+		wordLen := pos - wordStart
+		if wordLen > c.maxLen {
+			if !c.truncate {
+				continue
+			}
+			wordLen = c.maxLen
+		}
+		if wordLen < c.minLen {
+			continue
+		}
+
+		wordBuf = wordBuf[:wordLen]
+		if c.caseSensitive {
+			copy(wordBuf, data[wordStart:])
+		} else {
+			for i := range wordBuf {
+				wordBuf[i] = lowerTable[data[wordStart+i]]
+			}
+		}
+		c.add(wordBuf, data[wordStart:wordStart+wordLen])
+	}
+
+	return nil
+}
+
+// Result is the outcome of counting a stream with Tally.
+type Result struct {
+	Counts     map[string]int
+	TotalWords int64
+
+	// Pruned reports that Options.MaxUnique forced low-count words to be
+	// evicted, so Counts is approximate: TotalWords is still exact, but
+	// evicted words are missing and any count may be low by up to
+	// MaxUndercount occurrences.
+	Pruned        bool
+	MaxUndercount int
+
+	// Lengths[n] is the number of counted words that are n bytes long, or
+	// n characters with Options.Unicode. It is only filled in when
+	// Options.Lengths is set.
+	Lengths []int64
+
+	// Lines, Bytes and Chars are only filled in when Options.TextStats is
+	// set. Lines counts newline bytes. Chars counts UTF-8 characters when
+	// Options.Unicode is set and bytes otherwise.
+	Lines, Bytes, Chars int64
+
+	// Spellings records the original spellings of each counted word, as
+	// selected by Options.Spelling. It is nil unless that is set.
+	Spellings map[string]*Spellings
+
+	// FirstSeen lists the words of Counts in the order they were first
+	// counted. It is nil unless Options.FirstSeen is set.
+	FirstSeen []string
+
+	// Limited reports that counting stopped at Options.LimitBytes, so only
+	// a prefix of the input was counted. When reading a stream that ends
+	// just after the limit, it can be set although nothing was left.
+	Limited bool
+}
+
+// Merge adds the counts of other into r. The words other saw first that
+// are new to r follow those of r in FirstSeen.
+func (r *Result) Merge(other *Result) {
+	if r.Counts == nil {
+		r.Counts = make(map[string]int, len(other.Counts))
+	}
+	for _, word := range other.FirstSeen {
+		if _, ok := r.Counts[word]; !ok {
+			r.FirstSeen = append(r.FirstSeen, word)
+		}
+	}
+	for word, n := range other.Counts {
+		r.Counts[word] += n
+	}
+	r.TotalWords += other.TotalWords
+	r.Pruned = r.Pruned || other.Pruned
+	r.MaxUndercount += other.MaxUndercount
+	r.Lengths = addLengths(r.Lengths, other.Lengths)
+	r.Lines += other.Lines
+	r.Bytes += other.Bytes
+	r.Chars += other.Chars
+	r.Spellings = mergeSpellings(r.Spellings, other.Spellings)
+	r.Limited = r.Limited || other.Limited
+}
+
+// addLengths adds the histogram src into dst, growing dst as needed.
+func addLengths(dst, src []int64) []int64 {
+	for len(dst) < len(src) {
+		dst = append(dst, 0)
+	}
+	for n, c := range src {
+		dst[n] += c
+	}
+	return dst
+}
+
+// Count reads r to EOF and returns the occurrences of each word along with
+// the total number of words counted. If reading r fails, the words counted
+// before the failure are returned along with the error.
+func Count(r io.Reader, opts Options) (map[string]int, int64, error) {
+	res, err := Tally(r, opts, 1)
+	if res == nil {
+		return nil, 0, err
+	}
+	return res.Counts, res.TotalWords, err
+}
+
+// Tally reads r to EOF using the given number of goroutines (see
+// CountParallel) and returns the full Result. If reading r fails, the
+// Result for the input read so far is returned along with the error; a
+// word cut off by the failure is not counted.
+func Tally(r io.Reader, opts Options, workers int) (*Result, error) {
+	return TallyContext(context.Background(), r, opts, workers)
+}
+
+// TallyContext is like Tally but stops reading once ctx is done, checking
+// between chunks. It then returns the Result for the input read so far
+// together with ctx.Err(); a word cut off by the stop is not counted.
+func TallyContext(ctx context.Context, r io.Reader, opts Options, workers int) (*Result, error) {
+	r, limit := limitInput(r, opts)
+	var c *counter
+	var err error
+	if workers <= 1 || opts.sequential() {
+		c, err = count(ctx, newCounter(opts), r, opts)
+	} else {
+		c, err = countParallel(ctx, r, opts, workers)
+	}
+	if c == nil {
+		return nil, err
+	}
+	res := c.result()
+	res.Limited = limit.reached()
+	return res, err
+}
+
+// ScanWords reads r to EOF and calls fn for each word that Count would
+// count, in input order, after every filter in opts has been applied; with
+// NGram or Cooccur, fn receives each gram or pair instead. No counts are
+// kept. The word slice is reused for the next call, so fn must copy it to
+// retain it.
+func ScanWords(r io.Reader, opts Options, fn func(word []byte)) error {
+	c := newCounter(opts)
+	c.emit = fn
+	r, _ = limitInput(r, opts)
+	_, err := count(context.Background(), c, r, opts)
+	return err
+}
+
+// count is the sequential reader loop behind Count, adding the words of r
+// to c.
+func count(ctx context.Context, c *counter, r io.Reader, opts Options) (*counter, error) {
+	if opts.RecordSep != 0 {
+		return countRecords(ctx, c, r, opts)
+	}
+	buf := getReadBuffer(r, opts.readSize())
+	defer putReadBuffer(buf)
+	reader, chunk := buf.reader, buf.chunk
+	var leftover []byte
+	var offset int64
+	log := opts.debugLogger()
+	tok := newTokenizer(opts)
+	wordBuf := make([]byte, 0, opts.wordLimit())
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return c, err
+		}
+
+		// A read may return 0 bytes with io.EOF after the last data, so
+		// the pending leftover is still scanned with atEOF set below.
+		// The bytes of a read that also fails are still counted, and then
+		// the counts so far are returned with the error.
+		n, err := reader.Read(chunk)
+		failed := err != nil && err != io.EOF
+
+		c.countText(chunk[:n])
+		offset += int64(n)
+
+		var data []byte
+		if len(leftover) > 0 {
+			data = append(leftover, chunk[:n]...)
+			leftover = nil
+		} else {
+			data = chunk[:n]
+		}
+
+		var rest []byte
+		if opts.fastPath() {
+			rest = scanASCII(data, err == io.EOF, c, wordBuf)
+		} else {
+			rest = tok.scan(data, err == io.EOF, c)
+		}
+		if failed {
+			return c, err
+		}
+		if len(rest) > 0 {
+			if log != nil {
+				log.Debug("carrying partial word to next read", "offset", offset-int64(len(rest)), "bytes", len(rest))
+			}
+			// Only as much of an overlong word as settles its kept
+			// prefix is carried, so a run of letters spanning many
+			// reads is not rescanned from its start on each one.
+			if opts.fastPath() {
+				rest = rest[:min(len(rest), opts.wordLimit()+1)]
+			} else {
+				rest = tok.trimCarry(rest)
+			}
+			leftover = append([]byte(nil), rest...)
+		}
+
+		if err == io.EOF {
+			break
+		}
+	}
+
+	return c, nil
+}
+
+// Sort returns the entries of counts ordered by descending count, with ties
+// broken alphabetically so the result is stable for a given input.
+func Sort(counts map[string]int) []WordCount {
+	return SortBy(counts, ByCount)
+}
+
+// SortBy returns the entries of counts ordered by less. Every comparator
+// below ends with a comparison of the words themselves, which are unique,
+// so each defines a total order and the result does not depend on map
+// iteration order.
+func SortBy(counts map[string]int, less func(a, b WordCount) bool) []WordCount {
+	sorted := make([]WordCount, 0, len(counts))
+
+	for word, count := range counts {
+		sorted = append(sorted, WordCount{word, count})
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return less(sorted[i], sorted[j])
+	})
+
+	return sorted
+}
+
+// TopK returns the first k entries of counts in the order given by less,
+// as SortBy(counts, less)[:k] would, without building and sorting a slice
+// of every entry: it keeps the best k seen so far in a heap, so it needs
+// memory for only k entries beyond counts. With k <= 0, or k at least the
+// number of entries, it is SortBy.
+func TopK(counts map[string]int, k int, less func(a, b WordCount) bool) []WordCount {
+	if k <= 0 || k >= len(counts) {
+		return SortBy(counts, less)
+	}
+
+	// h is a heap whose root is the entry that would come last.
+	h := make([]WordCount, 0, k)
+	after := func(i, j int) bool { return less(h[j], h[i]) }
+	down := func(i int) {
+		for {
+			child := 2*i + 1
+			if child >= len(h) {
+				return
+			}
+			if child+1 < len(h) && after(child+1, child) {
+				child++
+			}
+			if !after(child, i) {
+				return
+			}
+			h[i], h[child] = h[child], h[i]
+			i = child
+		}
+	}
+	for word, count := range counts {
+		wc := WordCount{word, count}
+		if len(h) < k {
+			h = append(h, wc)
+			for i := len(h) - 1; i > 0 && after(i, (i-1)/2); i = (i - 1) / 2 {
+				h[i], h[(i-1)/2] = h[(i-1)/2], h[i]
+			}
+			continue
+		}
+		if less(wc, h[0]) {
+			h[0] = wc
+			down(0)
+		}
+	}
+
+	sort.Slice(h, func(i, j int) bool { return less(h[i], h[j]) })
+	return h
+}
+
+// ByCount orders by descending count, then ascending word. This is the
+// order used by Sort.
+func ByCount(a, b WordCount) bool {
+	if a.Count != b.Count {
+		return a.Count > b.Count
+	}
+	return a.Word < b.Word
+}
+
+// ByCountAsc orders by ascending count, then ascending word.
+func ByCountAsc(a, b WordCount) bool {
+	if a.Count != b.Count {
+		return a.Count < b.Count
+	}
+	return a.Word < b.Word
+}
+
+// ByWord orders alphabetically by byte value.
+func ByWord(a, b WordCount) bool {
+	return a.Word < b.Word
+}
+
+// ByLength orders by descending length in bytes, then as ByCount.
+func ByLength(a, b WordCount) bool {
+	if len(a.Word) != len(b.Word) {
+		return len(a.Word) > len(b.Word)
+	}
+	return ByCount(a, b)
+}
@@ -0,0 +1,144 @@
+package wordfreq
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+var errRead = errors.New("read failed")
+
+// failingReader returns its data in one Read, together with err.
+type failingReader struct {
+	data string
+	err  error
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	if r.data == "" {
+		return n, r.err
+	}
+	return n, nil
+}
+
+func TestCountReadError(t *testing.T) {
+	const text = "alpha beta alpha gam"
+	want := map[string]int{"alpha": 2, "beta": 1}
+	readers := []struct {
+		name string
+		r    func() io.Reader
+	}{
+		{"error after data", func() io.Reader {
+			return io.MultiReader(strings.NewReader(text), iotest.ErrReader(errRead))
+		}},
+		{"error with data", func() io.Reader { return &failingReader{data: text, err: errRead} }},
+	}
+	for _, reader := range readers {
+		for _, opts := range []Options{{}, {Unicode: true}} {
+			for _, workers := range []int{1, testWorkers} {
+				counts, total, err := CountParallel(reader.r(), opts, workers)
+				if !errors.Is(err, errRead) {
+					t.Errorf("%s, %+v, %d workers: error %v, want %v", reader.name, opts, workers, err, errRead)
+				}
+				if total != 3 || !maps.Equal(counts, want) {
+					t.Errorf("%s, %+v, %d workers: got %v (%d words), want %v; the word cut off by the error is dropped", reader.name, opts, workers, counts, total, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSortDeterministic(t *testing.T) {
+	// Many words share each count, so the order rests on tie-breaking.
+	data := generateSample(2 * parallelBlockSize)
+	counts, _, err := Count(bytes.NewReader(data), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Sort(counts)
+	for _, less := range []func(a, b WordCount) bool{ByCount, ByCountAsc, ByWord, ByLength} {
+		if !slices.Equal(SortBy(counts, less), SortBy(maps.Clone(counts), less)) {
+			t.Errorf("two sorts of the same counts differ")
+		}
+	}
+
+	for _, h := range hashes {
+		for _, workers := range []int{2, 3, testWorkers, 8} {
+			counts, _, err := CountParallel(bytes.NewReader(data), Options{Hash: h}, workers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := Sort(counts); !slices.Equal(got, want) {
+				t.Errorf("%v, %d workers: sorted output differs from a sequential count", h, workers)
+			}
+			if got := TopK(counts, 50, ByCount); !slices.Equal(got, want[:50]) {
+				t.Errorf("%v, %d workers: TopK differs from the head of Sort", h, workers)
+			}
+		}
+	}
+}
+
+// branchLower and branchIsLetter are the range checks that lowerTable and
+// letterBytes replace.
+func branchLower(b byte) byte {
+	if b >= 'A' && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+	return b
+}
+
+func branchIsLetter(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+func TestLookupTables(t *testing.T) {
+	for i := 0; i < 256; i++ {
+		b := byte(i)
+		if lowerTable[b] != branchLower(b) {
+			t.Errorf("lowerTable[%#x] = %#x, want %#x", b, lowerTable[b], branchLower(b))
+		}
+		if letterBytes[b] != branchIsLetter(b) {
+			t.Errorf("letterBytes[%#x] = %v, want %v", b, letterBytes[b], branchIsLetter(b))
+		}
+	}
+}
+
+// sink keeps the lookup benchmarks' results alive.
+var sink int
+
+// BenchmarkLookup compares the per-byte classification and lowercasing of
+// scanASCII done with the lookup tables and with range checks.
+func BenchmarkLookup(b *testing.B) {
+	data := sample()[:1<<20]
+	b.Run("table", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			n := 0
+			for _, c := range data {
+				if letterBytes[c] {
+					n += int(lowerTable[c])
+				}
+			}
+			sink += n
+		}
+	})
+	b.Run("branch", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		for i := 0; i < b.N; i++ {
+			n := 0
+			for _, c := range data {
+				if branchIsLetter(c) {
+					n += int(branchLower(c))
+				}
+			}
+			sink += n
+		}
+	})
+}